@@ -0,0 +1,32 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package api
+
+import (
+	"net/http"
+
+	"github.com/go-vela/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StreamLogs represents the API handler to stream the
+// lines captured from the steps running on the worker
+// over a websocket as they are scanned.
+func StreamLogs(c *gin.Context) {
+	// capture the broadcaster if log streaming is enabled
+	value, _ := c.Get("broadcaster")
+
+	b, ok := value.(http.Handler)
+	if !ok {
+		msg := "log streaming is not enabled on the worker"
+
+		c.AbortWithStatusJSON(http.StatusNotFound, types.Error{Message: &msg})
+
+		return
+	}
+
+	b.ServeHTTP(c.Writer, c.Request)
+}
@@ -7,10 +7,27 @@ package api
 import (
 	"net/http"
 
+	"github.com/gin-gonic/gin"
+
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
-// Metrics returns a Prometheus handler for serving go metrics
-func Metrics() http.Handler {
-	return promhttp.Handler()
+// defaultMetrics is the handler serving the go metrics
+// when no handler is attached to the context.
+var defaultMetrics = promhttp.Handler()
+
+// Metrics represents the API handler to serve the go metrics
+// and the metrics gathered from the registries attached to
+// the context, such as the metrics captured from executing
+// pipelines and pulling items from the queue.
+func Metrics(c *gin.Context) {
+	// capture the handler for the metrics
+	value, _ := c.Get("metrics")
+
+	handler, ok := value.(http.Handler)
+	if !ok {
+		handler = defaultMetrics
+	}
+
+	handler.ServeHTTP(c.Writer, c.Request)
 }
@@ -5,6 +5,8 @@
 package main
 
 import (
+	"net/http"
+
 	"github.com/go-vela/sdk-go/vela"
 
 	log "github.com/sirupsen/logrus"
@@ -15,7 +17,14 @@ import (
 func setupClient(c *cli.Context) (*vela.Client, error) {
 	log.Debug("Creating vela client from CLI configuration")
 
-	vela, err := vela.NewClient(c.String("server-addr"), nil)
+	var httpClient *http.Client
+
+	// check if the logs should be compressed when uploading
+	if c.Bool("server-log-compression") {
+		httpClient = &http.Client{Transport: newGzipTransport(nil)}
+	}
+
+	vela, err := vela.NewClient(c.String("server-addr"), httpClient)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/sirupsen/logrus"
+)
+
+// gzipTransport is an http.RoundTripper that compresses
+// the payloads for uploading logs to the server.
+//
+// If the server rejects the encoding with a 415 status code,
+// the logs are resent uncompressed and compression is disabled.
+type gzipTransport struct {
+	base     http.RoundTripper
+	disabled int32
+}
+
+// newGzipTransport returns a transport that compresses
+// the payloads for uploading logs sent with the base.
+func newGzipTransport(base http.RoundTripper) *gzipTransport {
+	// check if a base transport is provided
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &gzipTransport{base: base}
+}
+
+// RoundTrip sends the request with a compressed payload
+// when the request is uploading logs to the server.
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// check if the request should be compressed
+	if req.Body == nil ||
+		req.Method != http.MethodPut ||
+		!strings.HasSuffix(req.URL.Path, "/logs") ||
+		atomic.LoadInt32(&t.disabled) == 1 {
+		return t.base.RoundTrip(req)
+	}
+
+	// capture the payload from the request
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body.Close()
+
+	// compress the payload from the request
+	compressed, err := compress(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// create a copy of the request with the compressed payload
+	gzipReq := req.Clone(req.Context())
+	gzipReq.Body = ioutil.NopCloser(bytes.NewReader(compressed))
+	gzipReq.ContentLength = int64(len(compressed))
+	gzipReq.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := t.base.RoundTrip(gzipReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// check if the server supports the encoding
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		return resp, nil
+	}
+
+	logrus.Warn("server does not support gzip encoding for logs: disabling log compression")
+
+	atomic.StoreInt32(&t.disabled, 1)
+
+	resp.Body.Close()
+
+	// create a copy of the request with the original payload
+	plainReq := req.Clone(req.Context())
+	plainReq.Body = ioutil.NopCloser(bytes.NewReader(body))
+	plainReq.ContentLength = int64(len(body))
+
+	return t.base.RoundTrip(plainReq)
+}
+
+// compress is a helper function to
+// compress the payload with gzip.
+func compress(payload []byte) ([]byte, error) {
+	buf := new(bytes.Buffer)
+
+	// create a gzip writer for the payload
+	w := gzip.NewWriter(buf)
+
+	_, err := w.Write(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	// flush the compressed payload to the buffer
+	err = w.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
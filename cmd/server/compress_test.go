@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompress_gzipTransport(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		supported bool
+		path      string
+		encoding  string
+	}{
+		{supported: true, path: "/api/v1/repos/github/octocat/builds/1/steps/1/logs", encoding: "gzip"},
+		{supported: false, path: "/api/v1/repos/github/octocat/builds/1/steps/1/logs", encoding: ""},
+		{supported: true, path: "/api/v1/repos/github/octocat/builds/1/steps/1", encoding: ""},
+	}
+
+	want := []byte(`{"data":"aGVsbG8K"}`)
+
+	// run tests
+	for _, test := range tests {
+		var (
+			got      []byte
+			encoding string
+		)
+
+		// capture the decoded payload sent to the server
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body := r.Body
+
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				if !test.supported {
+					w.WriteHeader(http.StatusUnsupportedMediaType)
+
+					return
+				}
+
+				reader, err := gzip.NewReader(r.Body)
+				if err != nil {
+					t.Errorf("NewReader returned err: %v", err)
+				}
+
+				body = reader
+			}
+
+			encoding = r.Header.Get("Content-Encoding")
+			got, _ = ioutil.ReadAll(body)
+		}))
+
+		client := &http.Client{Transport: newGzipTransport(nil)}
+
+		req, _ := http.NewRequest(http.MethodPut, s.URL+test.path, bytes.NewReader(want))
+
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Do for %s returned err: %v", test.path, err)
+		}
+
+		resp.Body.Close()
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("payload for %s is %s, want %s", test.path, got, want)
+		}
+
+		if encoding != test.encoding {
+			t.Errorf("Content-Encoding for %s is %q, want %q", test.path, encoding, test.encoding)
+		}
+
+		s.Close()
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// consumer controls whether the worker pops new builds from
+// the queue, so operators are able to pause the worker for
+// maintenance without stopping the builds it is executing.
+type consumer struct {
+	mu sync.Mutex
+	// paused is closed while the consumer is paused
+	paused chan struct{}
+	// resumed is closed while the consumer is not paused
+	resumed chan struct{}
+}
+
+// newConsumer returns a consumer that is not paused.
+func newConsumer() *consumer {
+	resumed := make(chan struct{})
+	close(resumed)
+
+	return &consumer{
+		paused:  make(chan struct{}),
+		resumed: resumed,
+	}
+}
+
+// Pause stops new builds from being popped from the queue.
+func (c *consumer) Pause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the consumer is already paused
+	if c.isPaused() {
+		return
+	}
+
+	close(c.paused)
+	c.resumed = make(chan struct{})
+}
+
+// Resume allows new builds to be popped from the queue.
+func (c *consumer) Resume() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the consumer is not paused
+	if !c.isPaused() {
+		return
+	}
+
+	close(c.resumed)
+	c.paused = make(chan struct{})
+}
+
+// Paused returns if new builds are stopped
+// from being popped from the queue.
+func (c *consumer) Paused() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.isPaused()
+}
+
+// isPaused is a helper function to check if the
+// consumer is paused while the lock is held.
+func (c *consumer) isPaused() bool {
+	select {
+	case <-c.paused:
+		return true
+	default:
+		return false
+	}
+}
+
+// wait blocks while the consumer is paused
+// or until the context provided is done.
+func (c *consumer) wait(ctx context.Context) error {
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-resumed:
+		return nil
+	}
+}
+
+// context returns a context that is canceled when the
+// consumer is paused, so a pop waiting for a build is
+// stopped once the worker is paused.
+func (c *consumer) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	c.mu.Lock()
+	paused := c.paused
+	c.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	go func() {
+		select {
+		case <-paused:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
@@ -6,6 +6,9 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/go-vela/sdk-go/vela"
 	"github.com/go-vela/types/constants"
@@ -13,21 +16,179 @@ import (
 	"github.com/go-vela/worker/executor"
 	"github.com/go-vela/worker/executor/linux"
 	"github.com/go-vela/worker/runtime"
+	"github.com/go-vela/worker/secret/vault"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/urfave/cli"
 )
 
+// executorFlags represents the flags for configuring the executor.
+var executorFlags = []cli.Flag{
+	cli.StringFlag{
+		EnvVar: "VELA_EXECUTOR_DRIVER,EXECUTOR_DRIVER",
+		Name:   "executor-driver",
+		Usage:  "executor driver",
+		Value:  "linux",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_THREADS,EXECUTOR_THREADS",
+		Name:   "executor-threads",
+		Usage:  "number of executor threads to create",
+		Value:  1,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_TIMEOUT,EXECUTOR_TIMEOUT",
+		Name:   "executor-timeout",
+		Usage:  "max time an executor will run a build",
+		Value:  60 * time.Minute,
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_EXECUTOR_INIT_STEP,EXECUTOR_INIT_STEP",
+		Name:   "executor-init-step",
+		Usage:  "name of the step used to initialize the pipeline",
+		Value:  "init",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_BUFFER_SIZE,EXECUTOR_LOG_BUFFER_SIZE",
+		Name:   "executor-log-buffer-size",
+		Usage:  "number of bytes captured from a container before uploading logs",
+		Value:  1000,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_FLUSH_INTERVAL,EXECUTOR_LOG_FLUSH_INTERVAL",
+		Name:   "executor-log-flush-interval",
+		Usage:  "max time logs captured from a container are buffered before uploading",
+		Value:  5 * time.Second,
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_LINE_MAX_SIZE,EXECUTOR_LOG_LINE_MAX_SIZE",
+		Name:   "executor-log-line-max-size",
+		Usage:  "max number of bytes in a line captured from a step container before the line is split",
+		Value:  1024 * 1024,
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_MAX_SIZE,EXECUTOR_LOG_MAX_SIZE",
+		Name:   "executor-log-max-size",
+		Usage:  "max number of bytes captured from a step container before the logs are truncated",
+		Value:  0,
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_RAW,EXECUTOR_LOG_RAW",
+		Name:   "executor-log-raw",
+		Usage:  "upload the output of step containers as raw bytes instead of lines for binary output",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_RETRIES,EXECUTOR_LOG_RETRIES",
+		Name:   "executor-log-retries",
+		Usage:  "number of times uploading logs is retried after a transient failure",
+		Value:  3,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_RETRY_BACKOFF,EXECUTOR_LOG_RETRY_BACKOFF",
+		Name:   "executor-log-retry-backoff",
+		Usage:  "time waited before the first retry of uploading logs",
+		Value:  500 * time.Millisecond,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_SHUTDOWN_TIMEOUT,EXECUTOR_SHUTDOWN_TIMEOUT",
+		Name:   "executor-shutdown-timeout",
+		Usage:  "max time a running step is allowed to complete when the worker shuts down",
+		Value:  30 * time.Second,
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_MAX_PARALLEL_STEPS,EXECUTOR_MAX_PARALLEL_STEPS",
+		Name:   "executor-max-parallel-steps",
+		Usage:  "max number of step containers running at once across all stages (0 does not limit the steps)",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_STEP_CONCURRENCY,EXECUTOR_STEP_CONCURRENCY",
+		Name:   "executor-step-concurrency",
+		Usage:  "number of steps within a stage executed at once (1 executes steps sequentially)",
+		Value:  1,
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_EXECUTOR_STEP_RETRIES,EXECUTOR_STEP_RETRIES",
+		Name:   "executor-step-retries",
+		Usage:  "number of times a step container exiting non-zero is run again before the step fails",
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_STEP_TIMEOUT,EXECUTOR_STEP_TIMEOUT",
+		Name:   "executor-step-timeout",
+		Usage:  "max time a step container is allowed to run (0 runs until the build timeout)",
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_EXECUTOR_STEP_UPDATE_INTERVAL,EXECUTOR_STEP_UPDATE_INTERVAL",
+		Name:   "executor-step-update-interval",
+		Usage:  "time step status updates are batched before being sent to the server (0 sends every update)",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LENIENT_SUBSTITUTION,EXECUTOR_LENIENT_SUBSTITUTION",
+		Name:   "executor-lenient-substitution",
+		Usage:  "leave unresolved environment variables intact instead of failing the step",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_STDOUT,EXECUTOR_LOG_STDOUT",
+		Name:   "executor-log-stdout",
+		Usage:  "write the step logs to standard output in addition to the server",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_WEBSOCKET,EXECUTOR_LOG_WEBSOCKET",
+		Name:   "executor-log-websocket",
+		Usage:  "stream the step logs to subscribers over a websocket at /api/v1/logs",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_STRIP_ANSI,EXECUTOR_LOG_STRIP_ANSI",
+		Name:   "executor-log-strip-ansi",
+		Usage:  "remove ANSI escape sequences, like colors, from the step logs",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_LOG_TIMESTAMPS,EXECUTOR_LOG_TIMESTAMPS",
+		Name:   "executor-log-timestamps",
+		Usage:  "prefix each line captured from a step container with a timestamp",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_EXECUTOR_ENVIRONMENT,EXECUTOR_ENVIRONMENT",
+		Name:   "executor-environment",
+		Usage:  "environment variables injected into every step (<key>=<value>)",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_EXECUTOR_DRY_RUN,EXECUTOR_DRY_RUN",
+		Name:   "executor-dry-run",
+		Usage:  "log the resolved steps without running the containers",
+	},
+}
+
+// secretFlags represents the flags for configuring the
+// secrets resolved for the steps run by the executor.
+var secretFlags = []cli.Flag{
+	cli.StringFlag{
+		EnvVar: "VELA_SECRET_VAULT_ADDR,SECRET_VAULT_ADDR",
+		Name:   "secret-vault-addr",
+		Usage:  "address of the Vault instance used to resolve step secrets (<scheme>://<host>)",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_SECRET_VAULT_TOKEN,SECRET_VAULT_TOKEN",
+		Name:   "secret-vault-token",
+		Usage:  "token used for reading secrets from Vault",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_SECRET_VAULT_PREFIX,SECRET_VAULT_PREFIX",
+		Name:   "secret-vault-prefix",
+		Usage:  "path prefix in Vault the step secrets are read under (use secret/data for KV version 2)",
+		Value:  "secret",
+	},
+}
+
 // helper function to setup the queue from the CLI arguments.
-func setupExecutor(c *cli.Context, client *vela.Client, runtime runtime.Engine) (executor.Engine, error) {
+func setupExecutor(c *cli.Context, client *vela.Client, runtime runtime.Engine, broadcaster *linux.Broadcaster) (executor.Engine, error) {
 	logrus.Debug("Creating executor clients from CLI configuration")
 
 	switch c.String("executor-driver") {
 	case constants.DriverDarwin:
 		return setupDarwin(c, client, runtime)
 	case constants.DriverLinux:
-		return setupLinux(c, client, runtime)
+		return setupLinux(c, client, runtime, broadcaster)
 	case constants.DriverWindows:
 		return setupWindows(c, client, runtime)
 	default:
@@ -43,9 +204,60 @@ func setupDarwin(c *cli.Context, client *vela.Client, runtime runtime.Engine) (e
 }
 
 // helper function to setup the Linux executor from the CLI arguments.
-func setupLinux(c *cli.Context, client *vela.Client, runtime runtime.Engine) (executor.Engine, error) {
+func setupLinux(c *cli.Context, client *vela.Client, runtime runtime.Engine, broadcaster *linux.Broadcaster) (executor.Engine, error) {
 	logrus.Tracef("Creating %s executor client from CLI configuration", constants.DriverLinux)
-	return linux.New(client, runtime)
+
+	// create the Linux executor client
+	e, err := linux.New(client, runtime)
+	if err != nil {
+		return nil, err
+	}
+
+	e.WithDryRun(c.Bool("executor-dry-run"))
+	e.WithGlobalEnvironment(environment(c.StringSlice("executor-environment")))
+	e.WithInitStep(c.String("executor-init-step"))
+	e.WithLenientSubstitution(c.Bool("executor-lenient-substitution"))
+	e.WithLogBufferSize(c.Int("executor-log-buffer-size"))
+	e.WithLogFlushInterval(c.Duration("executor-log-flush-interval"))
+	e.WithLogLineMaxSize(c.Int("executor-log-line-max-size"))
+	e.WithLogMaxSize(c.Int("executor-log-max-size"))
+	e.WithLogRaw(c.Bool("executor-log-raw"))
+	e.WithLogRetries(c.Int("executor-log-retries"))
+	e.WithLogRetryBackoff(c.Duration("executor-log-retry-backoff"))
+	e.WithLogStripANSI(c.Bool("executor-log-strip-ansi"))
+	e.WithLogTimestamps(c.Bool("executor-log-timestamps"))
+	e.WithMaxParallelSteps(c.Int("executor-max-parallel-steps"))
+	e.WithShutdownTimeout(c.Duration("executor-shutdown-timeout"))
+	e.WithStepConcurrency(c.Int("executor-step-concurrency"))
+	e.WithStepRetries(c.Int("executor-step-retries"))
+	e.WithStepTimeout(c.Duration("executor-step-timeout"))
+	e.WithStepUpdateInterval(c.Duration("executor-step-update-interval"))
+
+	// check if secrets are resolved from Vault
+	if len(c.String("secret-vault-addr")) > 0 {
+		v, err := vault.New(
+			c.String("secret-vault-addr"),
+			c.String("secret-vault-token"),
+			c.String("secret-vault-prefix"),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		e.WithSecretResolver(v)
+	}
+
+	// check if the logs should be written to standard output
+	if c.Bool("executor-log-stdout") {
+		e.WithLogSinks(linux.NewWriterSink(os.Stdout))
+	}
+
+	// check if the logs should be streamed over a websocket
+	if broadcaster != nil {
+		e.WithLogBroadcaster(broadcaster)
+	}
+
+	return e, nil
 }
 
 // helper function to setup the Windows executor from the CLI arguments.
@@ -54,3 +266,22 @@ func setupWindows(c *cli.Context, client *vela.Client, runtime runtime.Engine) (
 	// return windows.New(client, runtime)
 	return nil, fmt.Errorf("unsupported executor driver: %s", constants.DriverWindows)
 }
+
+// helper function to parse the environment
+// variables in the form <key>=<value>.
+func environment(vars []string) map[string]string {
+	env := make(map[string]string)
+
+	for _, v := range vars {
+		parts := strings.SplitN(v, "=", 2)
+
+		// check if the variable has a value
+		if len(parts) != 2 {
+			continue
+		}
+
+		env[parts[0]] = parts[1]
+	}
+
+	return env
+}
@@ -6,7 +6,6 @@ package main
 
 import (
 	"os"
-	"time"
 
 	"github.com/go-vela/worker/version"
 
@@ -33,68 +32,35 @@ func main() {
 			Usage:  "set log level - options: (trace|debug|info|warn|error|fatal|panic)",
 			Value:  "info",
 		},
+		cli.StringFlag{
+			EnvVar: "VELA_LOG_FORMAT,LOG_FORMAT",
+			Name:   "log-format",
+			Usage:  "set log format - options: (json|text)",
+			Value:  "json",
+		},
 		cli.StringFlag{
 			EnvVar: "VELA_ADDR,VELA_HOST",
 			Name:   "server-addr",
 			Usage:  "server address as a fully qualified url (<scheme>://<host>)",
 		},
+		cli.BoolFlag{
+			EnvVar: "VELA_SERVER_LOG_COMPRESSION,SERVER_LOG_COMPRESSION",
+			Name:   "server-log-compression",
+			Usage:  "compress the logs uploaded to the server with gzip",
+		},
 		cli.StringFlag{
 			EnvVar: "VELA_SECRET",
 			Name:   "vela-secret",
 			Usage:  "secret used for server <-> worker communication",
 		},
-
-		// Executor Flags
-		cli.StringFlag{
-			EnvVar: "VELA_EXECUTOR_DRIVER,EXECUTOR_DRIVER",
-			Name:   "executor-driver",
-			Usage:  "executor driver",
-			Value:  "linux",
-		},
-		cli.IntFlag{
-			EnvVar: "VELA_EXECUTOR_THREADS,EXECUTOR_THREADS",
-			Name:   "executor-threads",
-			Usage:  "number of executor threads to create",
-			Value:  1,
-		},
-		cli.DurationFlag{
-			EnvVar: "VELA_EXECUTOR_TIMEOUT,EXECUTOR_TIMEOUT",
-			Name:   "executor-timeout",
-			Usage:  "max time an executor will run a build",
-			Value:  60 * time.Minute,
-		},
-
-		// Queue Flags
-		cli.StringFlag{
-			EnvVar: "VELA_QUEUE_DRIVER,QUEUE_DRIVER",
-			Name:   "queue-driver",
-			Usage:  "queue driver",
-		},
-		cli.StringFlag{
-			EnvVar: "VELA_QUEUE_CONFIG,QUEUE_CONFIG",
-			Name:   "queue-config",
-			Usage:  "queue driver configuration string",
-		},
-		cli.BoolFlag{
-			EnvVar: "VELA_QUEUE_CLUSTER,QUEUE_CLUSTER",
-			Name:   "queue-cluster",
-			Usage:  "queue client is setup for clusters",
-		},
-		// By default all builds are pushed to the "vela" route
-		cli.StringSliceFlag{
-			EnvVar: "VELA_QUEUE_WORKER_ROUTES,QUEUE_WORKER_ROUTES",
-			Name:   "queue-worker-routes",
-			Usage:  "queue worker routes is configuration for routing builds",
-		},
-
-		// Runtime Flags
-		cli.StringFlag{
-			EnvVar: "VELA_RUNTIME_DRIVER,RUNTIME_DRIVER",
-			Name:   "runtime-driver",
-			Usage:  "runtime driver",
-		},
 	}
 
+	// add the flags for configuring the executor, queue, runtime and secrets
+	app.Flags = append(app.Flags, executorFlags...)
+	app.Flags = append(app.Flags, queueFlags...)
+	app.Flags = append(app.Flags, runtimeFlags...)
+	app.Flags = append(app.Flags, secretFlags...)
+
 	// set logrus to log in JSON format
 	log.SetFormatter(&log.JSONFormatter{})
 
@@ -6,11 +6,15 @@ package main
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"github.com/go-vela/types"
+
 	"github.com/go-vela/worker/executor"
 
 	"github.com/go-vela/worker/queue"
@@ -20,101 +24,256 @@ import (
 )
 
 func operate(q queue.Service, e map[int]executor.Engine, t time.Duration) (err error) {
+	// create a context that is canceled when
+	// the worker receives a signal to shut down
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sigchan := make(chan os.Signal, 1)
+	signal.Notify(sigchan, syscall.SIGTERM)
+
+	defer signal.Stop(sigchan)
+
+	go func() {
+		select {
+		case <-sigchan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	// create a consumer that is paused with SIGUSR1
+	// and resumed with SIGUSR2 for maintenance
+	pause := newConsumer()
+
+	pausechan := make(chan os.Signal, 1)
+	signal.Notify(pausechan, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	defer signal.Stop(pausechan)
+
+	go func() {
+		for {
+			select {
+			case sig := <-pausechan:
+				if sig == syscall.SIGUSR1 {
+					logrus.Info("pausing worker from pulling builds from queue")
+					pause.Pause()
+
+					continue
+				}
+
+				logrus.Info("resuming worker pulling builds from queue")
+				pause.Resume()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
 	threads := new(errgroup.Group)
 
 	for id, executor := range e {
+		// https://golang.org/doc/faq#closures_and_goroutines
+		id, executor := id, executor
+
 		logrus.Infof("Thread ID %d listening to queue...", id)
 		threads.Go(func() error {
-			for {
-				// pop an item from the queue
-				item, err := q.Pop()
-				if err != nil {
-					return err
-				}
+			err := work(ctx, q, pause, executor, t)
+			if err != nil {
+				return err
+			}
 
-				// create logger with extra metadata
-				logger := logrus.WithFields(logrus.Fields{
-					"build": item.Build.GetNumber(),
-					"repo":  item.Repo.GetFullName(),
-				})
-
-				// add build metadata to the executor
-				executor.WithBuild(item.Build)
-				executor.WithPipeline(item.Pipeline)
-				executor.WithRepo(item.Repo)
-				executor.WithUser(item.User)
-
-				// check if the repository has a custom timeout
-				if item.Repo.GetTimeout() > 0 {
-					// update timeout variable to repository custom timeout
-					t = time.Duration(item.Repo.GetTimeout()) * time.Minute
-				}
+			logrus.Infof("Thread ID %d stopped listening to queue", id)
 
-				ctx := context.Background()
-
-				// add to the background context with a timeout
-				// built in for ensuring a build doesn't run forever
-				ctx, timeout := context.WithTimeout(ctx, t)
-				defer timeout()
-
-				// add signals to the parent context so
-				// users can cancel builds
-				sigchan := make(chan os.Signal, 1)
-				ctx, sig := context.WithCancel(ctx)
-				signal.Notify(sigchan, syscall.SIGTERM)
-				defer func() {
-					signal.Stop(sigchan)
-					sig()
-				}()
-				go func() {
-					select {
-					case <-sigchan:
-						sig()
-					case <-ctx.Done():
-					}
-				}()
-
-				defer func() {
-					// destroy the build on the executor
-					logger.Info("destroying build")
-					err = executor.DestroyBuild(context.Background())
-					if err != nil {
-						logger.Errorf("unable to destroy build: %v", err)
-					}
-				}()
-
-				// create the build on the executor
-				logger.Info("creating build")
-				err = executor.CreateBuild(ctx)
-				if err != nil {
-					logger.Errorf("unable to create build: %v", err)
-					return err
-				}
+			return nil
+		})
+	}
 
-				// execute the build on the executor
-				logger.Info("executing build")
-				err = executor.ExecBuild(ctx)
-				if err != nil {
-					logger.Errorf("unable to execute build: %v", err)
-					return err
-				}
+	err = threads.Wait()
+	if err != nil {
+		return err
+	}
 
-				// destroy the build on the executor
-				logger.Info("destroying build")
-				err = executor.DestroyBuild(context.Background())
-				if err != nil {
-					logger.Errorf("unable to destroy build: %v", err)
-				}
+	return nil
+}
 
-				logger.Info("completed build")
+// helper function to execute the builds from the queue
+// until the context is canceled when the worker shuts down.
+//
+// No builds are popped from the queue while the consumer is paused.
+func work(ctx context.Context, q queue.Service, pause *consumer, executor executor.Engine, t time.Duration) error {
+	for {
+		// wait while the consumer is paused
+		err := pause.wait(ctx)
+		if err != nil {
+			return nil
+		}
+
+		// create a context to stop the pop when the consumer is paused
+		popCtx, stop := pause.context(ctx)
+
+		// pop an item from the queue
+		item, channel, err := q.Pop(popCtx)
+
+		stop()
+
+		if errors.Is(err, context.Canceled) {
+			// check if the pop was stopped since the consumer is paused
+			if ctx.Err() == nil {
+				continue
 			}
-		})
+
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// check if the worker is shutting down before the build started
+		if ctx.Err() != nil {
+			return requeue(q, item, channel)
+		}
+
+		// execute the build from the item
+		err = exec(ctx, q, item, executor, t)
+
+		// acknowledge the item once the build has been executed
+		//
+		// the context for the worker may be canceled so a new
+		// context is used to ensure the item is acknowledged
+		ackErr := q.Ack(context.Background(), item)
+		if ackErr != nil {
+			logrus.Errorf("unable to ack build %d for %s: %v", item.Build.GetNumber(), item.Repo.GetFullName(), ackErr)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		// check if the worker is shutting down
+		if ctx.Err() != nil {
+			return nil
+		}
 	}
+}
 
-	err = threads.Wait()
+// helper function to return the item to the channel
+// it was popped from so another worker can execute it.
+func requeue(q queue.Service, item *types.Item, channel string) error {
+	logrus.Infof("requeuing build %d for %s to %s", item.Build.GetNumber(), item.Repo.GetFullName(), channel)
+
+	// return the item to the queue
+	//
+	// the context for the worker is canceled so a new
+	// context is used to ensure the item is returned
+	err := q.Nack(context.Background(), item)
+	if err != nil {
+		return fmt.Errorf("unable to requeue build %d for %s: %w", item.Build.GetNumber(), item.Repo.GetFullName(), err)
+	}
+
+	return nil
+}
+
+// helper function to execute the build from the item on the executor.
+//
+// The build is stopped when the context is canceled because
+// the worker received a signal to shut down, allowing the
+// running step to complete, or killed when a cancel for the
+// build is received from the queue.
+func exec(ctx context.Context, q queue.Service, item *types.Item, executor executor.Engine, t time.Duration) error {
+	var err error
+
+	// create logger with extra metadata
+	logger := logrus.WithFields(logrus.Fields{
+		"build": item.Build.GetNumber(),
+		"repo":  item.Repo.GetFullName(),
+	})
+
+	// add build metadata to the executor
+	executor.WithBuild(item.Build)
+	executor.WithPipeline(item.Pipeline)
+	executor.WithRepo(item.Repo)
+	executor.WithUser(item.User)
+
+	// check if the repository has a custom timeout
+	if item.Repo.GetTimeout() > 0 {
+		// update timeout variable to repository custom timeout
+		t = time.Duration(item.Repo.GetTimeout()) * time.Minute
+	}
+
+	// add to the background context with a timeout
+	// built in for ensuring a build doesn't run forever
+	buildCtx, timeout := context.WithTimeout(context.Background(), t)
+	defer timeout()
+
+	// listen for a cancel for the build from the queue
+	canceled, err := q.Canceled(buildCtx, buildKey(item))
 	if err != nil {
+		logger.Errorf("unable to listen for build cancel: %v", err)
+	}
+
+	// stop the build when the worker shuts down or it is canceled
+	go func() {
+		select {
+		case <-ctx.Done():
+			timeout()
+		case <-canceled:
+			logger.Info("canceling build")
+
+			// kill the build to stop the running step immediately
+			_, err := executor.KillBuild()
+			if err != nil {
+				logger.Warnf("unable to kill build: %v", err)
+
+				// stop the build that is not executing yet
+				timeout()
+			}
+		case <-buildCtx.Done():
+		}
+	}()
+
+	defer func() {
+		// destroy the build on the executor
+		logger.Info("destroying build")
+
+		err := executor.DestroyBuild(context.Background())
+		if err != nil {
+			logger.Errorf("unable to destroy build: %v", err)
+		}
+
+		// log the result of the build and its steps
+		summary := executor.SummarizeBuild()
+		for _, s := range summary.Steps {
+			logger.Infof("%s step completed with status %s and exit code %d in %v", s.Name, s.Status, s.ExitCode, s.Duration)
+		}
+
+		logger.Infof("completed build with status %s in %v", summary.Status, summary.Duration)
+	}()
+
+	// create the build on the executor
+	logger.Info("creating build")
+
+	err = executor.CreateBuild(buildCtx)
+	if err != nil {
+		logger.Errorf("unable to create build: %v", err)
+		return err
+	}
+
+	// execute the build on the executor
+	logger.Info("executing build")
+
+	err = executor.ExecBuild(buildCtx)
+	if err != nil {
+		logger.Errorf("unable to execute build: %v", err)
 		return err
 	}
 
 	return nil
 }
+
+// helper function to create the key identifying
+// the build from the item for canceling the build.
+func buildKey(item *types.Item) string {
+	return fmt.Sprintf("%s/%d", item.Repo.GetFullName(), item.Build.GetNumber())
+}
@@ -0,0 +1,371 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mockserver "github.com/go-vela/mock/server"
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/types/constants"
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/go-vela/worker/executor"
+	"github.com/go-vela/worker/executor/linux"
+
+	"github.com/go-vela/worker/queue"
+	"github.com/go-vela/worker/queue/memory"
+
+	"github.com/go-vela/worker/runtime"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestOperate_work_Requeue(t *testing.T) {
+	// setup types
+	q, _ := memory.New([]string{"vela"})
+
+	err := q.Push(context.Background(), "vela", []byte(`{"build":{"number":1},"repo":{"full_name":"github/octocat"}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// run test
+	err = work(ctx, &shutdownQueue{Service: q, cancel: cancel}, newConsumer(), nil, time.Minute)
+	if err != nil {
+		t.Errorf("work returned err: %v", err)
+	}
+
+	length, _ := q.Length(context.Background(), "vela")
+	if length != 1 {
+		t.Errorf("Length is %v, want 1", length)
+	}
+
+	got, _, err := q.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want 1", got.Build.GetNumber())
+	}
+}
+
+func TestOperate_work_Pause(t *testing.T) {
+	// setup types
+	q, _ := memory.New([]string{"vela"})
+
+	err := q.Push(context.Background(), "vela", []byte(`{"build":{"number":1},"repo":{"full_name":"github/octocat"}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sq := &shutdownQueue{Service: q, cancel: cancel}
+
+	pause := newConsumer()
+	pause.Pause()
+
+	result := make(chan error, 1)
+
+	// run test
+	go func() {
+		result <- work(ctx, sq, pause, nil, time.Minute)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	if atomic.LoadInt32(&sq.pops) != 0 {
+		t.Errorf("work popped %d items while paused, want 0", atomic.LoadInt32(&sq.pops))
+	}
+
+	pause.Resume()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("work returned err: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("work should have popped the item once resumed")
+	}
+
+	if atomic.LoadInt32(&sq.pops) != 1 {
+		t.Errorf("work popped %d items once resumed, want 1", atomic.LoadInt32(&sq.pops))
+	}
+}
+
+func TestOperate_work_PauseWhilePopping(t *testing.T) {
+	// setup types
+	q, _ := memory.New([]string{"vela"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sq := &shutdownQueue{Service: q, cancel: cancel}
+
+	pause := newConsumer()
+
+	result := make(chan error, 1)
+
+	// run test
+	go func() {
+		result <- work(ctx, sq, pause, nil, time.Minute)
+	}()
+
+	// wait for the worker to wait on the empty queue
+	time.Sleep(50 * time.Millisecond)
+
+	pause.Pause()
+
+	err := q.Push(context.Background(), "vela", []byte(`{"build":{"number":1},"repo":{"full_name":"github/octocat"}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	length, _ := q.Length(context.Background(), "vela")
+	if length != 1 {
+		t.Errorf("Length is %v while paused, want 1", length)
+	}
+
+	cancel()
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("work returned err: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("work should have stopped once the worker shut down")
+	}
+}
+
+func TestOperate_exec_Cancel(t *testing.T) {
+	// setup types
+	q, _ := memory.New([]string{"vela"})
+
+	e := &blockingEngine{started: make(chan struct{})}
+
+	item := &types.Item{
+		Build: &library.Build{Number: vela.Int(1)},
+		Repo:  &library.Repo{FullName: vela.String("github/octocat")},
+	}
+
+	result := make(chan error, 1)
+
+	// run test
+	go func() {
+		result <- exec(context.Background(), q, item, e, time.Minute)
+	}()
+
+	// wait for the build to start executing
+	select {
+	case <-e.started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("exec should have executed the build")
+	}
+
+	err := q.Cancel(context.Background(), "github/octocat/1")
+	if err != nil {
+		t.Errorf("Cancel returned err: %v", err)
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("exec returned err: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("exec should have stopped the canceled build")
+	}
+
+	if !errors.Is(e.err, context.Canceled) {
+		t.Errorf("ExecBuild context err is %v, want %v", e.err, context.Canceled)
+	}
+}
+
+func TestOperate_exec_CancelKilled(t *testing.T) {
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(mockserver.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	q, _ := memory.New([]string{"vela"})
+
+	mock, _ := docker.NewMock()
+	r := &waitingRuntime{Engine: mock, started: make(chan struct{})}
+
+	e, _ := linux.New(c, r)
+
+	item := &types.Item{
+		Build: &library.Build{Number: vela.Int(1)},
+		Repo:  &library.Repo{Org: vela.String("github"), Name: vela.String("octocat"), FullName: vela.String("github/octocat")},
+		Pipeline: &pipeline.Build{
+			Version: "1",
+			ID:      "__0",
+			Steps: pipeline.ContainerSlice{
+				&pipeline.Container{
+					ID:          "__0_init",
+					Environment: map[string]string{},
+					Image:       "#init",
+					Name:        "init",
+					Number:      1,
+				},
+				&pipeline.Container{
+					ID:          "__0_sleep",
+					Environment: map[string]string{},
+					Image:       "alpine:latest",
+					Name:        "sleep",
+					Number:      2,
+				},
+			},
+		},
+	}
+
+	result := make(chan error, 1)
+
+	// run test
+	go func() {
+		result <- exec(context.Background(), q, item, e, time.Minute)
+	}()
+
+	// wait for the step to start running
+	select {
+	case <-r.started:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("exec should have run the step")
+	}
+
+	err := q.Cancel(context.Background(), "github/octocat/1")
+	if err != nil {
+		t.Errorf("Cancel returned err: %v", err)
+	}
+
+	// the step is stopped without waiting for the shutdown timeout
+	select {
+	case err := <-result:
+		if err != nil {
+			t.Errorf("exec returned err: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("exec should have killed the canceled build")
+	}
+
+	got := e.SummarizeBuild()
+
+	if got.Status != constants.StatusKilled {
+		t.Errorf("exec build status is %s, want %s", got.Status, constants.StatusKilled)
+	}
+}
+
+// blockingEngine is an executor that
+// runs the build until it is stopped.
+type blockingEngine struct {
+	executor.Engine
+
+	started chan struct{}
+	kill    context.CancelFunc
+	err     error
+}
+
+// WithBuild sets the library build type in the executor.
+func (e *blockingEngine) WithBuild(*library.Build) executor.Engine { return e }
+
+// WithPipeline sets the pipeline build type in the executor.
+func (e *blockingEngine) WithPipeline(*pipeline.Build) executor.Engine { return e }
+
+// WithRepo sets the library repo type in the executor.
+func (e *blockingEngine) WithRepo(*library.Repo) executor.Engine { return e }
+
+// WithUser sets the library user type in the executor.
+func (e *blockingEngine) WithUser(*library.User) executor.Engine { return e }
+
+// CreateBuild prepares the build for execution.
+func (e *blockingEngine) CreateBuild(context.Context) error { return nil }
+
+// ExecBuild runs the build until the context is done.
+func (e *blockingEngine) ExecBuild(ctx context.Context) error {
+	ctx, e.kill = context.WithCancel(ctx)
+
+	close(e.started)
+
+	<-ctx.Done()
+	e.err = ctx.Err()
+
+	return nil
+}
+
+// KillBuild kills the build in execution.
+func (e *blockingEngine) KillBuild() (*library.Build, error) {
+	e.kill()
+
+	return nil, nil
+}
+
+// DestroyBuild cleans up the build after execution.
+func (e *blockingEngine) DestroyBuild(context.Context) error { return nil }
+
+// SummarizeBuild captures the result of the build.
+func (e *blockingEngine) SummarizeBuild() *executor.BuildSummary { return new(executor.BuildSummary) }
+
+// shutdownQueue is a queue that shuts down
+// the worker once an item is popped.
+type shutdownQueue struct {
+	queue.Service
+
+	cancel context.CancelFunc
+	pops   int32
+}
+
+// Pop grabs an item off the queue and shuts down the worker.
+func (q *shutdownQueue) Pop(ctx context.Context, channels ...string) (*types.Item, string, error) {
+	atomic.AddInt32(&q.pops, 1)
+
+	item, channel, err := q.Service.Pop(ctx, channels...)
+	if err == nil {
+		q.cancel()
+	}
+
+	return item, channel, err
+}
+
+// waitingRuntime is a runtime that waits
+// on a container until the context is done.
+type waitingRuntime struct {
+	runtime.Engine
+
+	once    sync.Once
+	started chan struct{}
+}
+
+// WaitContainer waits on the container until the context is done.
+func (r *waitingRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	r.once.Do(func() { close(r.started) })
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
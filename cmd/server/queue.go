@@ -6,16 +6,142 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-vela/types/constants"
 
 	"github.com/go-vela/worker/queue"
+	"github.com/go-vela/worker/queue/memory"
 	"github.com/go-vela/worker/queue/redis"
 
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
+// queueFlags represents the flags for configuring the queue.
+var queueFlags = []cli.Flag{
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_DRIVER,QUEUE_DRIVER",
+		Name:   "queue-driver",
+		Usage:  "queue driver",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_CONFIG,QUEUE_CONFIG",
+		Name:   "queue-config",
+		Usage:  "queue driver configuration string",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_QUEUE_CLUSTER,QUEUE_CLUSTER",
+		Name:   "queue-cluster",
+		Usage:  "queue client is setup for clusters",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_SENTINEL_MASTER,QUEUE_SENTINEL_MASTER",
+		Name:   "queue-sentinel-master",
+		Usage:  "name of the master node monitored by the queue sentinel nodes",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_QUEUE_SENTINEL_ADDRS,QUEUE_SENTINEL_ADDRS",
+		Name:   "queue-sentinel-addrs",
+		Usage:  "addresses of the queue sentinel nodes (<host>:<port>)",
+	},
+	// By default all builds are pushed to the "vela" route
+	cli.StringSliceFlag{
+		EnvVar: "VELA_QUEUE_WORKER_ROUTES,QUEUE_WORKER_ROUTES",
+		Name:   "queue-worker-routes",
+		Usage:  "queue worker routes is configuration for routing builds",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_QUEUE_WORKER_PRIORITIES,QUEUE_WORKER_PRIORITIES",
+		Name:   "queue-worker-priorities",
+		Usage:  "priorities for draining the queue worker routes (<route>=<priority>)",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_QUEUE_POOL_SIZE,QUEUE_POOL_SIZE",
+		Name:   "queue-pool-size",
+		Usage:  "max number of active connections to the queue (0 uses the client default)",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_QUEUE_MIN_IDLE_CONNS,QUEUE_MIN_IDLE_CONNS",
+		Name:   "queue-min-idle-conns",
+		Usage:  "number of idle connections to keep open to the queue",
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_IDLE_TIMEOUT,QUEUE_IDLE_TIMEOUT",
+		Name:   "queue-idle-timeout",
+		Usage:  "max time an idle connection to the queue is kept open (0 uses the client default)",
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_PUSH_TIMEOUT,QUEUE_PUSH_TIMEOUT",
+		Name:   "queue-push-timeout",
+		Usage:  "max time to wait when pushing an item to the queue (0 waits indefinitely)",
+		Value:  10 * time.Second,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_RECONNECT_BACKOFF,QUEUE_RECONNECT_BACKOFF",
+		Name:   "queue-reconnect-backoff",
+		Usage:  "time waited before the first attempt to reconnect to the queue (0 disables reconnecting)",
+		Value:  time.Second,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_RECONNECT_MAX_BACKOFF,QUEUE_RECONNECT_MAX_BACKOFF",
+		Name:   "queue-reconnect-max-backoff",
+		Usage:  "max time waited between attempts to reconnect to the queue",
+		Value:  30 * time.Second,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_KEEPALIVE_INTERVAL,QUEUE_KEEPALIVE_INTERVAL",
+		Name:   "queue-keepalive-interval",
+		Usage:  "time waited between pings of idle connections to the queue (0 disables the keepalive)",
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_QUEUE_STALE_TIMEOUT,QUEUE_STALE_TIMEOUT",
+		Name:   "queue-stale-timeout",
+		Usage:  "time a popped build stays in processing without a heartbeat before it is requeued (0 disables requeuing)",
+		Value:  5 * time.Minute,
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_USERNAME,QUEUE_USERNAME",
+		Name:   "queue-username",
+		Usage:  "ACL user to authenticate to the queue as (overrides the user in the queue config)",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_PASSWORD,QUEUE_PASSWORD",
+		Name:   "queue-password",
+		Usage:  "password to authenticate to the queue with (overrides the password in the queue config)",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_QUEUE_DB,QUEUE_DB",
+		Name:   "queue-db",
+		Usage:  "database index selected for the queue (0 uses the database from the queue config)",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_QUEUE_TLS,QUEUE_TLS",
+		Name:   "queue-tls",
+		Usage:  "enables TLS for the connection to the queue",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_TLS_CA_CERT,QUEUE_TLS_CA_CERT",
+		Name:   "queue-tls-ca-cert",
+		Usage:  "path to the CA certificate used to verify the queue",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_TLS_CERT,QUEUE_TLS_CERT",
+		Name:   "queue-tls-cert",
+		Usage:  "path to the client certificate used for mutual TLS with the queue",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_QUEUE_TLS_KEY,QUEUE_TLS_KEY",
+		Name:   "queue-tls-key",
+		Usage:  "path to the client key used for mutual TLS with the queue",
+	},
+	cli.BoolFlag{
+		EnvVar: "VELA_QUEUE_TLS_SKIP_VERIFY,QUEUE_TLS_SKIP_VERIFY",
+		Name:   "queue-tls-skip-verify",
+		Usage:  "skips verification of the queue certificate for self-signed setups",
+	},
+}
+
 // helper function to setup the queue from the CLI arguments.
 func setupQueue(c *cli.Context) (queue.Service, error) {
 	logrus.Debug("Creating queue client from CLI configuration")
@@ -25,6 +151,8 @@ func setupQueue(c *cli.Context) (queue.Service, error) {
 		return setupKafka(c)
 	case constants.DriverRedis:
 		return setupRedis(c)
+	case memory.Driver:
+		return setupMemory(c)
 	default:
 		return nil, fmt.Errorf("invalid queue driver: %s", c.String("queue-driver"))
 	}
@@ -37,17 +165,56 @@ func setupKafka(c *cli.Context) (queue.Service, error) {
 	return nil, fmt.Errorf("unsupported queue driver: %s", constants.DriverKafka)
 }
 
+// helper function to setup the in-memory queue from the CLI arguments.
+func setupMemory(c *cli.Context) (queue.Service, error) {
+	logrus.Tracef("Creating %s queue client from CLI configuration", memory.Driver)
+
+	// setup routes
+	routes := append(c.StringSlice("queue-worker-routes"), constants.DefaultRoute)
+
+	return memory.New(routes)
+}
+
 // helper function to setup the Redis queue from the CLI arguments.
 func setupRedis(c *cli.Context) (queue.Service, error) {
 	// setup routes
 	routes := append(c.StringSlice("queue-worker-routes"), constants.DefaultRoute)
 
+	// setup options
+	opts := []redis.ClientOpt{
+		redis.WithPoolSize(c.Int("queue-pool-size")),
+		redis.WithMinIdleConns(c.Int("queue-min-idle-conns")),
+		redis.WithIdleTimeout(c.Duration("queue-idle-timeout")),
+		redis.WithPushTimeout(c.Duration("queue-push-timeout")),
+		redis.WithPriorities(c.StringSlice("queue-worker-priorities")),
+		redis.WithReconnectBackoff(c.Duration("queue-reconnect-backoff"), c.Duration("queue-reconnect-max-backoff")),
+		redis.WithKeepalive(c.Duration("queue-keepalive-interval")),
+		redis.WithStaleTimeout(c.Duration("queue-stale-timeout")),
+		redis.WithDB(c.Int("queue-db")),
+		redis.WithAuth(c.String("queue-username"), c.String("queue-password")),
+	}
+
+	// check if TLS is enabled for the queue
+	if c.Bool("queue-tls") {
+		opts = append(opts, redis.WithTLS(
+			c.String("queue-tls-ca-cert"),
+			c.String("queue-tls-cert"),
+			c.String("queue-tls-key"),
+			c.Bool("queue-tls-skip-verify"),
+		))
+	}
+
+	if len(c.StringSlice("queue-sentinel-addrs")) > 0 {
+		logrus.Tracef("Creating %s queue sentinel client from CLI configuration", constants.DriverRedis)
+		return redis.NewSentinel(c.String("queue-sentinel-master"), c.StringSlice("queue-sentinel-addrs"), routes, opts...)
+	}
+
 	if c.Bool("queue-cluster") {
 		logrus.Tracef("Creating %s queue cluster client from CLI configuration", constants.DriverRedis)
-		return redis.NewCluster(c.String("queue-config"), routes...)
+		return redis.NewCluster(c.String("queue-config"), routes, opts...)
 	}
 
 	logrus.Tracef("Creating %s queue client from CLI configuration", constants.DriverRedis)
 
-	return redis.New(c.String("queue-config"), routes...)
+	return redis.New(c.String("queue-config"), routes, opts...)
 }
@@ -6,6 +6,7 @@ package main
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-vela/types/constants"
 
@@ -16,6 +17,78 @@ import (
 	"github.com/urfave/cli"
 )
 
+// runtimeFlags represents the flags for configuring the runtime.
+var runtimeFlags = []cli.Flag{
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_DRIVER,RUNTIME_DRIVER",
+		Name:   "runtime-driver",
+		Usage:  "runtime driver",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_PULL_POLICY,RUNTIME_PULL_POLICY",
+		Name:   "runtime-pull-policy",
+		Usage:  "policy for pulling images - options: (always|if-not-present|never)",
+		Value:  "if-not-present",
+	},
+	cli.IntFlag{
+		EnvVar: "VELA_RUNTIME_PULL_RETRIES,RUNTIME_PULL_RETRIES",
+		Name:   "runtime-pull-retries",
+		Usage:  "number of times pulling an image is retried after a transient failure",
+		Value:  3,
+	},
+	cli.DurationFlag{
+		EnvVar: "VELA_RUNTIME_PULL_RETRY_BACKOFF,RUNTIME_PULL_RETRY_BACKOFF",
+		Name:   "runtime-pull-retry-backoff",
+		Usage:  "time waited before the first retry of pulling an image",
+		Value:  time.Second,
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_RUNTIME_PRIVILEGED_IMAGES,RUNTIME_PRIVILEGED_IMAGES",
+		Name:   "runtime-privileged-images",
+		Usage:  "allowlist of images that are able to run privileged",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_RUNTIME_ULIMITS,RUNTIME_ULIMITS",
+		Name:   "runtime-ulimits",
+		Usage:  "resource limits applied to step containers (<name>=<soft>[:<hard>])",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_RUNTIME_CAP_ADD,RUNTIME_CAP_ADD",
+		Name:   "runtime-cap-add",
+		Usage:  "Linux capabilities added to step containers",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_RUNTIME_CAP_DROP,RUNTIME_CAP_DROP",
+		Name:   "runtime-cap-drop",
+		Usage:  "Linux capabilities dropped from step containers (ALL drops every capability)",
+	},
+	cli.StringSliceFlag{
+		EnvVar: "VELA_RUNTIME_CACHE_VOLUMES,RUNTIME_CACHE_VOLUMES",
+		Name:   "runtime-cache-volumes",
+		Usage:  "host paths and named volumes step containers are allowed to mount",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_CONTAINER_PREFIX,RUNTIME_CONTAINER_PREFIX",
+		Name:   "runtime-container-prefix",
+		Usage:  "prefix added to the name of step containers to avoid collisions on the host",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_REGISTRY,RUNTIME_REGISTRY",
+		Name:   "runtime-registry",
+		Usage:  "registry the credentials are used for when pulling images",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_REGISTRY_USERNAME,RUNTIME_REGISTRY_USERNAME",
+		Name:   "runtime-registry-username",
+		Usage:  "username used for pulling images from the registry",
+	},
+	cli.StringFlag{
+		EnvVar: "VELA_RUNTIME_REGISTRY_PASSWORD,RUNTIME_REGISTRY_PASSWORD",
+		Name:   "runtime-registry-password",
+		Usage:  "password used for pulling images from the registry",
+	},
+}
+
 // helper function to setup the runtime from the CLI arguments.
 func setupRuntime(c *cli.Context) (runtime.Engine, error) {
 	logrus.Debug("Creating runtime client from CLI configuration")
@@ -33,7 +106,44 @@ func setupRuntime(c *cli.Context) (runtime.Engine, error) {
 // helper function to setup the Docker runtime from the CLI arguments.
 func setupDocker(c *cli.Context) (runtime.Engine, error) {
 	logrus.Tracef("Creating %s runtime client from CLI configuration", constants.DriverDocker)
-	return docker.New()
+
+	// create the Docker runtime client
+	r, err := docker.New()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.WithPrivilegedImages(c.StringSlice("runtime-privileged-images"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.WithUlimits(c.StringSlice("runtime-ulimits"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.WithCacheVolumes(c.StringSlice("runtime-cache-volumes"))
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = r.WithContainerPrefix(c.String("runtime-container-prefix"))
+	if err != nil {
+		return nil, err
+	}
+
+	r.WithCapabilities(c.StringSlice("runtime-cap-add"), c.StringSlice("runtime-cap-drop"))
+	r.WithPullPolicy(c.String("runtime-pull-policy"))
+	r.WithPullRetries(c.Int("runtime-pull-retries"))
+	r.WithPullRetryBackoff(c.Duration("runtime-pull-retry-backoff"))
+	r.WithRegistryAuth(
+		c.String("runtime-registry"),
+		c.String("runtime-registry-username"),
+		c.String("runtime-registry-password"),
+	)
+
+	return r, nil
 }
 
 // helper function to setup the Docker runtime from the CLI arguments.
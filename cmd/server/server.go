@@ -10,10 +10,13 @@ import (
 	"time"
 
 	"github.com/go-vela/worker/executor"
+	"github.com/go-vela/worker/executor/linux"
+	"github.com/go-vela/worker/queue/redis"
 	"github.com/go-vela/worker/router"
 	"github.com/go-vela/worker/router/middleware"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 
 	"github.com/urfave/cli"
@@ -27,30 +30,8 @@ func server(c *cli.Context) error {
 		return err
 	}
 
-	// set log level for logrus
-	switch c.String("log-level") {
-	case "t", "trace", "Trace", "TRACE":
-		gin.SetMode(gin.DebugMode)
-		logrus.SetLevel(logrus.TraceLevel)
-	case "d", "debug", "Debug", "DEBUG":
-		gin.SetMode(gin.DebugMode)
-		logrus.SetLevel(logrus.DebugLevel)
-	case "i", "info", "Info", "INFO":
-		gin.SetMode(gin.ReleaseMode)
-		logrus.SetLevel(logrus.InfoLevel)
-	case "w", "warn", "Warn", "WARN":
-		gin.SetMode(gin.ReleaseMode)
-		logrus.SetLevel(logrus.WarnLevel)
-	case "e", "error", "Error", "ERROR":
-		gin.SetMode(gin.ReleaseMode)
-		logrus.SetLevel(logrus.ErrorLevel)
-	case "f", "fatal", "Fatal", "FATAL":
-		gin.SetMode(gin.ReleaseMode)
-		logrus.SetLevel(logrus.FatalLevel)
-	case "p", "panic", "Panic", "PANIC":
-		gin.SetMode(gin.ReleaseMode)
-		logrus.SetLevel(logrus.PanicLevel)
-	}
+	// setup the log level and format for logrus
+	setupLogging(c)
 
 	// create a vela client
 	vela, err := setupClient(c)
@@ -70,11 +51,26 @@ func server(c *cli.Context) error {
 		return err
 	}
 
+	// close the queue client when the worker shuts down
+	defer func() {
+		err := queue.Close()
+		if err != nil {
+			logrus.Errorf("unable to close queue: %v", err)
+		}
+	}()
+
+	// create the broadcaster for streaming the step logs
+	var broadcaster *linux.Broadcaster
+
+	if c.Bool("executor-log-websocket") {
+		broadcaster = linux.NewBroadcaster()
+	}
+
 	// create the executor clients
 	executors := make(map[int]executor.Engine)
 
 	for i := 0; i < c.Int("executor-threads"); i++ {
-		executor, err := setupExecutor(c, vela, runtime)
+		executor, err := setupExecutor(c, vela, runtime, broadcaster)
 		if err != nil {
 			return err
 		}
@@ -82,12 +78,8 @@ func server(c *cli.Context) error {
 		executors[i] = executor
 	}
 
-	router := router.Load(
-		middleware.RequestVersion,
-		middleware.Executor(executors),
-		middleware.Secret(c.String("vela-secret")),
-		middleware.Logger(logrus.StandardLogger(), time.RFC3339, true),
-	)
+	// setup the router with the executors
+	router := setupRouter(c, executors, broadcaster)
 
 	tomb := new(tomb.Tomb)
 	tomb.Go(func() error {
@@ -121,7 +113,67 @@ func server(c *cli.Context) error {
 	})
 
 	// Wait for stuff and watch for errors
-	tomb.Wait()
+	return tomb.Wait()
+}
+
+// helper function to setup the log level and format for logrus
+// from the provided CLI arguments.
+func setupLogging(c *cli.Context) {
+	// set log level for logrus
+	switch c.String("log-level") {
+	case "t", "trace", "Trace", "TRACE":
+		gin.SetMode(gin.DebugMode)
+		logrus.SetLevel(logrus.TraceLevel)
+	case "d", "debug", "Debug", "DEBUG":
+		gin.SetMode(gin.DebugMode)
+		logrus.SetLevel(logrus.DebugLevel)
+	case "i", "info", "Info", "INFO":
+		gin.SetMode(gin.ReleaseMode)
+		logrus.SetLevel(logrus.InfoLevel)
+	case "w", "warn", "Warn", "WARN":
+		gin.SetMode(gin.ReleaseMode)
+		logrus.SetLevel(logrus.WarnLevel)
+	case "e", "error", "Error", "ERROR":
+		gin.SetMode(gin.ReleaseMode)
+		logrus.SetLevel(logrus.ErrorLevel)
+	case "f", "fatal", "Fatal", "FATAL":
+		gin.SetMode(gin.ReleaseMode)
+		logrus.SetLevel(logrus.FatalLevel)
+	case "p", "panic", "Panic", "PANIC":
+		gin.SetMode(gin.ReleaseMode)
+		logrus.SetLevel(logrus.PanicLevel)
+	}
+
+	// set log format for logrus
+	switch c.String("log-format") {
+	case "t", "text", "Text", "TEXT":
+		logrus.SetFormatter(&logrus.TextFormatter{})
+	default:
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	}
+}
+
+// helper function to setup the router with the middleware
+// for the executors from the provided CLI arguments.
+func setupRouter(c *cli.Context, executors map[int]executor.Engine, broadcaster *linux.Broadcaster) http.Handler {
+	// setup the middleware for the router
+	middlewares := []gin.HandlerFunc{
+		middleware.RequestVersion,
+		middleware.Executor(executors),
+		middleware.Secret(c.String("vela-secret")),
+		middleware.Logger(logrus.StandardLogger(), time.RFC3339, true),
+		// gather metrics from the default, executor and queue registries
+		middleware.Metrics(prometheus.Gatherers{
+			prometheus.DefaultGatherer,
+			linux.Registry,
+			redis.Registry,
+		}),
+	}
+
+	// check if the logs are streamed over a websocket
+	if broadcaster != nil {
+		middlewares = append(middlewares, middleware.Broadcaster(broadcaster))
+	}
 
-	return tomb.Err()
+	return router.Load(middlewares...)
 }
@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/go-vela/worker/queue/memory"
+	"github.com/go-vela/worker/runtime/docker"
+
 	"github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -59,6 +62,12 @@ func validateCore(c *cli.Context) error {
 		return fmt.Errorf("server-addr (VELA_ADDR or VELA_HOST) flag must not have trailing slash")
 	}
 
+	switch c.String("log-format") {
+	case "t", "text", "Text", "TEXT", "j", "json", "Json", "JSON":
+	default:
+		return fmt.Errorf("log-format (VELA_LOG_FORMAT or LOG_FORMAT) flag improperly configured")
+	}
+
 	if len(c.String("vela-secret")) == 0 {
 		return fmt.Errorf("vela-secret (VELA_SECRET) flag not specified")
 	}
@@ -78,6 +87,64 @@ func validateExecutor(c *cli.Context) error {
 		return fmt.Errorf("executor-threads (VELA_EXECUTOR_THREADS or EXECUTOR_THREADS) flag improperly configured")
 	}
 
+	if len(c.String("secret-vault-addr")) > 0 && len(c.String("secret-vault-token")) == 0 {
+		return fmt.Errorf("secret-vault-token (VELA_SECRET_VAULT_TOKEN or SECRET_VAULT_TOKEN) flag not specified")
+	}
+
+	for _, env := range c.StringSlice("executor-environment") {
+		if !strings.Contains(env, "=") || strings.HasPrefix(env, "=") {
+			return fmt.Errorf("executor-environment (VELA_EXECUTOR_ENVIRONMENT or EXECUTOR_ENVIRONMENT) flag improperly configured")
+		}
+	}
+
+	if c.Int("executor-log-buffer-size") < 0 {
+		return fmt.Errorf("executor-log-buffer-size (VELA_EXECUTOR_LOG_BUFFER_SIZE or EXECUTOR_LOG_BUFFER_SIZE) flag improperly configured")
+	}
+
+	if c.Duration("executor-log-flush-interval") < 0 {
+		return fmt.Errorf("executor-log-flush-interval (VELA_EXECUTOR_LOG_FLUSH_INTERVAL or EXECUTOR_LOG_FLUSH_INTERVAL) flag improperly configured")
+	}
+
+	if c.Int("executor-log-line-max-size") < 1 {
+		return fmt.Errorf("executor-log-line-max-size (VELA_EXECUTOR_LOG_LINE_MAX_SIZE or EXECUTOR_LOG_LINE_MAX_SIZE) flag improperly configured")
+	}
+
+	if c.Int("executor-log-max-size") < 0 {
+		return fmt.Errorf("executor-log-max-size (VELA_EXECUTOR_LOG_MAX_SIZE or EXECUTOR_LOG_MAX_SIZE) flag improperly configured")
+	}
+
+	if c.Int("executor-log-retries") < 0 {
+		return fmt.Errorf("executor-log-retries (VELA_EXECUTOR_LOG_RETRIES or EXECUTOR_LOG_RETRIES) flag improperly configured")
+	}
+
+	if c.Duration("executor-log-retry-backoff") < 0 {
+		return fmt.Errorf("executor-log-retry-backoff (VELA_EXECUTOR_LOG_RETRY_BACKOFF or EXECUTOR_LOG_RETRY_BACKOFF) flag improperly configured")
+	}
+
+	if c.Duration("executor-shutdown-timeout") < 0 {
+		return fmt.Errorf("executor-shutdown-timeout (VELA_EXECUTOR_SHUTDOWN_TIMEOUT or EXECUTOR_SHUTDOWN_TIMEOUT) flag improperly configured")
+	}
+
+	if c.Int("executor-max-parallel-steps") < 0 {
+		return fmt.Errorf("executor-max-parallel-steps (VELA_EXECUTOR_MAX_PARALLEL_STEPS or EXECUTOR_MAX_PARALLEL_STEPS) flag improperly configured")
+	}
+
+	if c.Int("executor-step-concurrency") < 1 {
+		return fmt.Errorf("executor-step-concurrency (VELA_EXECUTOR_STEP_CONCURRENCY or EXECUTOR_STEP_CONCURRENCY) flag improperly configured")
+	}
+
+	if c.Int("executor-step-retries") < 0 {
+		return fmt.Errorf("executor-step-retries (VELA_EXECUTOR_STEP_RETRIES or EXECUTOR_STEP_RETRIES) flag improperly configured")
+	}
+
+	if c.Duration("executor-step-timeout") < 0 {
+		return fmt.Errorf("executor-step-timeout (VELA_EXECUTOR_STEP_TIMEOUT or EXECUTOR_STEP_TIMEOUT) flag improperly configured")
+	}
+
+	if c.Duration("executor-step-update-interval") < 0 {
+		return fmt.Errorf("executor-step-update-interval (VELA_EXECUTOR_STEP_UPDATE_INTERVAL or EXECUTOR_STEP_UPDATE_INTERVAL) flag improperly configured")
+	}
+
 	return nil
 }
 
@@ -89,10 +156,19 @@ func validateQueue(c *cli.Context) error {
 		return fmt.Errorf("queue-driver (VELA_QUEUE_DRIVER or QUEUE_DRIVER) flag not specified")
 	}
 
-	if len(c.String("queue-config")) == 0 {
+	// the in-memory queue requires no configuration
+	if c.String("queue-driver") == memory.Driver {
+		return nil
+	}
+
+	if len(c.String("queue-config")) == 0 && len(c.StringSlice("queue-sentinel-addrs")) == 0 {
 		return fmt.Errorf("queue-config (VELA_QUEUE_CONFIG or QUEUE_CONFIG) flag not specified")
 	}
 
+	if len(c.StringSlice("queue-sentinel-addrs")) > 0 && len(c.String("queue-sentinel-master")) == 0 {
+		return fmt.Errorf("queue-sentinel-master (VELA_QUEUE_SENTINEL_MASTER or QUEUE_SENTINEL_MASTER) flag not specified")
+	}
+
 	return nil
 }
 
@@ -104,5 +180,23 @@ func validateRuntime(c *cli.Context) error {
 		return fmt.Errorf("runtime-driver (VELA_RUNTIME_DRIVER or RUNTIME_DRIVER) flag not specified")
 	}
 
+	switch c.String("runtime-pull-policy") {
+	case docker.PullAlways, docker.PullIfNotPresent, docker.PullNever:
+	default:
+		return fmt.Errorf("runtime-pull-policy (VELA_RUNTIME_PULL_POLICY or RUNTIME_PULL_POLICY) flag improperly configured")
+	}
+
+	if c.Int("runtime-pull-retries") < 0 {
+		return fmt.Errorf("runtime-pull-retries (VELA_RUNTIME_PULL_RETRIES or RUNTIME_PULL_RETRIES) flag improperly configured")
+	}
+
+	if c.Duration("runtime-pull-retry-backoff") < 0 {
+		return fmt.Errorf("runtime-pull-retry-backoff (VELA_RUNTIME_PULL_RETRY_BACKOFF or RUNTIME_PULL_RETRY_BACKOFF) flag improperly configured")
+	}
+
+	if len(c.String("runtime-registry")) > 0 && len(c.String("runtime-registry-username")) == 0 {
+		return fmt.Errorf("runtime-registry-username (VELA_RUNTIME_REGISTRY_USERNAME or RUNTIME_REGISTRY_USERNAME) flag not specified")
+	}
+
 	return nil
 }
@@ -92,6 +92,9 @@ type Engine interface {
 	// DestroyBuild defines a function that
 	// cleans up the build after execution.
 	DestroyBuild(context.Context) error
+	// SummarizeBuild defines a function that captures
+	// the result of the build and its steps.
+	SummarizeBuild() *BuildSummary
 
 	// With Engine interface functions
 
@@ -0,0 +1,20 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"regexp"
+)
+
+// ansi matches the ANSI escape sequences used by terminals,
+// including the control sequences for colors and cursor
+// movement and the operating system commands for titles.
+var ansi = regexp.MustCompile(`\x1b(\[[0-?]*[ -/]*[@-~]|\][^\x07\x1b]*(\x07|\x1b\\)|[@-Z\\-_])`)
+
+// stripANSI removes the ANSI escape
+// sequences from the container output.
+func stripANSI(line []byte) []byte {
+	return ansi.ReplaceAll(line, nil)
+}
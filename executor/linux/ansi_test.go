@@ -0,0 +1,33 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"testing"
+)
+
+func TestLinux_stripANSI(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		line string
+		want string
+	}{
+		{line: "hello", want: "hello"},
+		{line: "\x1b[31mhello\x1b[0m", want: "hello"},
+		{line: "\x1b[1;32mhello\x1b[m world", want: "hello world"},
+		{line: "\x1b[2K\x1b[1Ghello", want: "hello"},
+		{line: "\x1b]0;title\x07hello", want: "hello"},
+		{line: "\x1b]0;title\x1b\\hello", want: "hello"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := stripANSI([]byte(test.line))
+
+		if string(got) != test.want {
+			t.Errorf("stripANSI is %q, want %q", got, test.want)
+		}
+	}
+}
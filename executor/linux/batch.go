@@ -0,0 +1,149 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-vela/types/library"
+)
+
+// updateStep is a helper function to send the step status to
+// the Vela server. While the step updates are batched, the
+// latest status for the step is held until the batch is flushed.
+func (c *client) updateStep(s *library.Step) error {
+	b := c.build
+	r := c.repo
+
+	c.updateMu.Lock()
+
+	// check if the step updates are batched
+	if c.updates != nil {
+		// hold a copy of the step so it is not
+		// changed while the batch is being sent
+		update := *s
+		c.updates[s.GetNumber()] = &update
+
+		c.updateMu.Unlock()
+
+		return nil
+	}
+
+	c.updateMu.Unlock()
+
+	// send API call to update the step
+	_, _, err := c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// batchSteps is a helper function to start batching the step
+// updates and flush them on the configured interval. The
+// returned function stops batching, flushes the updates
+// remaining in the batch and returns the updates that
+// could not be sent to the Vela server.
+func (c *client) batchSteps() func() error {
+	// check if the step updates are batched
+	if c.StepUpdateInterval <= 0 {
+		return func() error { return nil }
+	}
+
+	c.updateMu.Lock()
+	c.updates = make(map[int]*library.Step)
+	c.updateMu.Unlock()
+
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(c.StepUpdateInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				// the failed updates are retried with the next flush
+				err := c.flushSteps(false)
+				if err != nil {
+					c.logger.Warnf("unable to flush step updates: %v", err)
+				}
+			}
+		}
+	}()
+
+	return func() error {
+		close(done)
+		<-stopped
+
+		return c.flushSteps(true)
+	}
+}
+
+// flushSteps is a helper function to send the step updates
+// held in the batch to the Vela server in step order. The
+// batching is stopped once the updates are flushed if the
+// stop is true. Otherwise, the updates that could not be
+// sent are held in the batch unless a newer update for
+// the step was added while flushing.
+func (c *client) flushSteps(stop bool) error {
+	b := c.build
+	r := c.repo
+
+	c.updateMu.Lock()
+
+	updates := c.updates
+
+	// reset the batch for the next interval
+	if stop {
+		c.updates = nil
+	} else {
+		c.updates = make(map[int]*library.Step)
+	}
+
+	c.updateMu.Unlock()
+
+	// capture the steps held in the batch
+	numbers := []int{}
+	for number := range updates {
+		numbers = append(numbers, number)
+	}
+
+	sort.Ints(numbers)
+
+	errs := []error{}
+
+	for _, number := range numbers {
+		s := updates[number]
+
+		c.logger.Infof("uploading %s step state", s.GetName())
+		// send API call to update the step
+		_, _, err := c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
+		if err == nil {
+			continue
+		}
+
+		errs = append(errs, fmt.Errorf("unable to upload %s step state: %w", s.GetName(), err))
+
+		c.updateMu.Lock()
+
+		// hold the update for the next flush
+		if _, ok := c.updates[number]; c.updates != nil && !ok {
+			c.updates[number] = s
+		}
+
+		c.updateMu.Unlock()
+	}
+
+	return combineErrors(errs)
+}
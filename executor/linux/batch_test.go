@@ -0,0 +1,226 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/constants"
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExecutor_batchSteps(t *testing.T) {
+	// setup types
+	ctn := &pipeline.Container{
+		ID:          "__0_echo",
+		Environment: map[string]string{},
+		Image:       "alpine:latest",
+		Name:        "echo",
+		Number:      1,
+	}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu      sync.Mutex
+		updates []*library.Step
+	)
+
+	handler := server.FakeHandler()
+
+	// capture the step updates sent to the server
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1") {
+			body, _ := ioutil.ReadAll(req.Body)
+
+			step := new(library.Step)
+			_ = json.Unmarshal(body, step)
+
+			mu.Lock()
+			updates = append(updates, step)
+			mu.Unlock()
+
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	r, _ := docker.NewMock()
+
+	tests := []struct {
+		name     string
+		interval time.Duration
+		want     int
+	}{
+		{
+			name:     "unbatched",
+			interval: 0,
+			want:     4,
+		},
+		{
+			name:     "batched",
+			interval: time.Hour,
+			want:     1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		mu.Lock()
+		updates = nil
+		mu.Unlock()
+
+		e, _ := New(c, r)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+		e.WithStepUpdateInterval(test.interval)
+
+		flush := e.batchSteps()
+
+		// plan the step and report it as running, then complete and finished
+		err := e.PlanStep(context.Background(), ctn)
+		if err != nil {
+			t.Errorf("%s: PlanStep returned err: %v", test.name, err)
+		}
+
+		err = e.startStep(ctn)
+		if err != nil {
+			t.Errorf("%s: startStep returned err: %v", test.name, err)
+		}
+
+		err = e.reportStep(ctn, nil)
+		if err != nil {
+			t.Errorf("%s: reportStep returned err: %v", test.name, err)
+		}
+
+		result, _ := e.steps.Load(ctn.ID)
+		step := result.(*library.Step)
+		step.SetFinished(time.Now().UTC().Unix())
+
+		err = e.updateStep(step)
+		if err != nil {
+			t.Errorf("%s: updateStep returned err: %v", test.name, err)
+		}
+
+		err = flush()
+		if err != nil {
+			t.Errorf("%s: flush returned err: %v", test.name, err)
+		}
+
+		mu.Lock()
+
+		if len(updates) != test.want {
+			t.Errorf("%s: sent %d step updates, want %d", test.name, len(updates), test.want)
+		}
+
+		// check the latest state of the step was sent
+		if len(updates) > 0 {
+			got := updates[len(updates)-1]
+
+			if got.GetStatus() != constants.StatusSuccess || got.GetFinished() == 0 {
+				t.Errorf("%s: last step update is status %s finished %d, want %s finished", test.name, got.GetStatus(), got.GetFinished(), constants.StatusSuccess)
+			}
+		}
+
+		mu.Unlock()
+	}
+}
+
+func TestExecutor_batchSteps_Failure(t *testing.T) {
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var failing int32 = 1
+
+	handler := server.FakeHandler()
+
+	// fail the step updates sent to the server while failing
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1") &&
+			atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	r, _ := docker.NewMock()
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithStepUpdateInterval(time.Hour)
+
+	flush := e.batchSteps()
+
+	err := e.updateStep(&library.Step{Name: vela.String("echo"), Number: vela.Int(1)})
+	if err != nil {
+		t.Errorf("updateStep returned err: %v", err)
+	}
+
+	// run test
+	err = e.flushSteps(false)
+	if err == nil {
+		t.Errorf("flushSteps should have returned err")
+	}
+
+	if len(e.updates) != 1 {
+		t.Errorf("flushSteps held %d step updates, want 1", len(e.updates))
+	}
+
+	err = flush()
+	if err == nil {
+		t.Errorf("flush should have returned err")
+	}
+
+	if e.updates != nil {
+		t.Errorf("flush should have stopped batching the step updates")
+	}
+
+	atomic.StoreInt32(&failing, 0)
+
+	// check the step updates are sent once the server recovers
+	flush = e.batchSteps()
+
+	err = e.updateStep(&library.Step{Name: vela.String("echo"), Number: vela.Int(1)})
+	if err != nil {
+		t.Errorf("updateStep returned err: %v", err)
+	}
+
+	err = flush()
+	if err != nil {
+		t.Errorf("flush returned err: %v", err)
+	}
+}
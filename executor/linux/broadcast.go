@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/go-vela/types/pipeline"
+
+	"golang.org/x/net/websocket"
+)
+
+// subscriberBuffer defines the number of log lines buffered
+// for a subscriber before new lines are dropped for it.
+const subscriberBuffer = 100
+
+// LogBroadcaster represents a destination the log lines
+// are sent to as they are scanned from a step container.
+type LogBroadcaster interface {
+	// Broadcast sends the log line scanned from the step to the subscribers.
+	Broadcast(ctn *pipeline.Container, line []byte)
+}
+
+// Broadcaster is a LogBroadcaster implementation that streams
+// the log lines to the subscribers connected over a websocket.
+//
+// Subscribers are able to filter the lines to a single
+// step with the "step" query parameter.
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+// subscriber represents a websocket connection
+// receiving the log lines from the Broadcaster.
+type subscriber struct {
+	step  string
+	lines chan *LogLine
+}
+
+// LogLine represents a log line sent to the subscribers.
+type LogLine struct {
+	Step string `json:"step"`
+	Line string `json:"line"`
+}
+
+// NewBroadcaster returns a Broadcaster with no subscribers.
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{subscribers: make(map[*subscriber]struct{})}
+}
+
+// Broadcast sends the log line scanned from the step to the subscribers.
+//
+// The line is dropped for a subscriber that is not keeping up
+// so a slow connection does not block capturing the logs.
+func (b *Broadcaster) Broadcast(ctn *pipeline.Container, line []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	msg := &LogLine{Step: ctn.Name, Line: string(line)}
+
+	for s := range b.subscribers {
+		// check if the subscriber is filtering for another step
+		if len(s.step) > 0 && s.step != ctn.Name {
+			continue
+		}
+
+		select {
+		case s.lines <- msg:
+		default:
+		}
+	}
+}
+
+// ServeHTTP upgrades the request to a websocket
+// and streams the log lines to the connection.
+func (b *Broadcaster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	websocket.Handler(func(ws *websocket.Conn) {
+		s := b.subscribe(r.URL.Query().Get("step"))
+
+		// stop streaming once the connection is closed
+		go func() {
+			_, _ = io.Copy(ioutil.Discard, ws)
+
+			b.unsubscribe(s)
+		}()
+
+		for line := range s.lines {
+			err := websocket.JSON.Send(ws, line)
+			if err != nil {
+				b.unsubscribe(s)
+			}
+		}
+	}).ServeHTTP(w, r)
+}
+
+// subscribe is a helper function to add a
+// subscriber for the lines from the step.
+func (b *Broadcaster) subscribe(step string) *subscriber {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s := &subscriber{
+		step:  step,
+		lines: make(chan *LogLine, subscriberBuffer),
+	}
+
+	b.subscribers[s] = struct{}{}
+
+	return s
+}
+
+// unsubscribe is a helper function to remove the
+// subscriber and stop sending lines to it.
+func (b *Broadcaster) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// check if the subscriber was already removed
+	if _, ok := b.subscribers[s]; !ok {
+		return
+	}
+
+	delete(b.subscribers, s)
+	close(s.lines)
+}
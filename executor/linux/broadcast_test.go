@@ -0,0 +1,114 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestLinux_streamStep_LogBroadcaster(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	b := NewBroadcaster()
+
+	ws := httptest.NewServer(b)
+	defer ws.Close()
+
+	url := "ws" + strings.TrimPrefix(ws.URL, "http") + "?step=clone"
+
+	conn, err := websocket.Dial(url, "", ws.URL)
+	if err != nil {
+		t.Fatalf("unable to dial websocket: %v", err)
+	}
+
+	defer conn.Close()
+
+	// wait for the subscriber to be added
+	for i := 0; i < 100 && subscribers(b) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	e, _ := New(c, r)
+	e.WithLogBroadcaster(b)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	// run test
+	err = e.streamStep(context.Background(), &pipeline.Container{ID: "__0_test", Name: "test", Number: 2}, strings.NewReader("skipped\n"), new(library.Log))
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	rc, wc := io.Pipe()
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- e.streamStep(context.Background(), &pipeline.Container{ID: "__0_clone", Name: "clone", Number: 1}, rc, new(library.Log))
+	}()
+
+	// receive each line before the next line is written
+	for _, line := range []string{"hello", "world"} {
+		_, err = wc.Write([]byte(line + "\n"))
+		if err != nil {
+			t.Errorf("unable to write line: %v", err)
+		}
+
+		got := new(LogLine)
+
+		err = websocket.JSON.Receive(conn, got)
+		if err != nil {
+			t.Errorf("Receive returned err: %v", err)
+		}
+
+		want := &LogLine{Step: "clone", Line: line + "\n"}
+
+		if *got != *want {
+			t.Errorf("Receive is %v, want %v", got, want)
+		}
+	}
+
+	wc.Close()
+
+	err = <-errs
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+}
+
+// subscribers is a helper function to capture
+// the number of subscribers to the broadcaster.
+func subscribers(b *Broadcaster) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers)
+}
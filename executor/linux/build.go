@@ -6,13 +6,15 @@ package linux
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
-	"syscall"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/go-vela/worker/executor"
+
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
@@ -34,20 +36,8 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	})
 
 	defer func() {
-		// NOTE: When an error occurs during a build that does not have to do
-		// with a pipeline we should set build status to "error" not "failed"
-		// because it is worker related and not build.
-		if e != nil {
-			b.SetError(e.Error())
-			b.SetStatus(constants.StatusError)
-		}
-
-		c.logger.Info("uploading build state")
 		// send API call to update the build
-		_, _, err := c.Vela.Build.Update(r.GetOrg(), r.GetName(), b)
-		if err != nil {
-			c.logger.Errorf("unable to upload errorred state: %v", err)
-		}
+		c.uploadBuild(b, e)
 	}()
 
 	// update the build fields
@@ -56,7 +46,7 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	b.SetHost(c.Hostname)
 	// TODO: This should not be hardcoded
 	b.SetDistribution("linux")
-	b.SetRuntime("docker")
+	b.SetRuntime(c.Runtime.Name())
 
 	c.logger.Info("uploading build state")
 	// send API call to update the build
@@ -77,25 +67,18 @@ func (c *client) CreateBuild(ctx context.Context) error {
 		return fmt.Errorf("unable to pull secrets: %v", err)
 	}
 
+	var buildErr error
+
 	// TODO: make this better
 	init := new(pipeline.Container)
 	if len(p.Steps) > 0 {
 		init = p.Steps[0]
 
-		c.logger.Infof("creating %s step", init.Name)
-		// create the step
-		err = c.CreateStep(ctx, init)
-		if err != nil {
-			e = err
-			return fmt.Errorf("unable to create %s step: %w", init.Name, err)
-		}
-
-		c.logger.Infof("planning %s step", init.Name)
-		// plan the step
-		err = c.PlanStep(ctx, init)
+		// create and plan the init step
+		buildErr, err = c.createInit(ctx, init)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to plan %s step: %w", init.Name, err)
+			e = buildErr
+			return err
 		}
 	}
 
@@ -103,20 +86,11 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	if len(p.Stages) > 0 {
 		init = p.Stages[0].Steps[0]
 
-		c.logger.Infof("creating %s step", init.Name)
-		// create the step
-		err = c.CreateStep(ctx, init)
-		if err != nil {
-			e = err
-			return fmt.Errorf("unable to create %s step: %w", init.Name, err)
-		}
-
-		c.logger.Infof("planning %s step", init.Name)
-		// plan the step
-		err = c.PlanStep(ctx, init)
+		// create and plan the init step
+		buildErr, err = c.createInit(ctx, init)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to plan %s step: %w", init.Name, err)
+			e = buildErr
+			return err
 		}
 	}
 
@@ -138,28 +112,126 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	l := result.(*library.Log)
 
 	defer func() {
-		s.SetFinished(time.Now().UTC().Unix())
-		c.logger.Infof("uploading %s step state", init.Name)
-		// send API call to update the step
-		s, _, err = c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
-		if err != nil {
-			c.logger.Errorf("unable to upload %s state: %v", init.Name, err)
-		}
-
-		c.logger.Infof("uploading %s step logs", init.Name)
-		// send API call to update the logs for the step
-		l, _, err = c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), init.Number, l)
-		if err != nil {
-			c.logger.Errorf("unable to upload %s logs: %v", init.Name, err)
-		}
+		// send the init step state and logs to the Vela server
+		c.uploadInit(init, s, l)
 	}()
 
+	// create the resources for the pipeline
+	buildErr, err = c.createPipeline(ctx, l)
+	if err != nil {
+		e = buildErr
+		return err
+	}
+
+	return nil
+}
+
+// createPipeline is a helper function to create the runtime
+// resources, services, stages and steps for the pipeline.
+func (c *client) createPipeline(ctx context.Context, l *library.Log) (buildErr, err error) {
+	// create the runtime network and volume for the pipeline
+	buildErr, err = c.createRuntime(ctx, l)
+	if err != nil {
+		return buildErr, err
+	}
+
+	// create the services for the pipeline
+	buildErr, err = c.createServices(ctx, l)
+	if err != nil {
+		return buildErr, err
+	}
+
+	// create the stages for the pipeline
+	buildErr, err = c.createStages(ctx, l)
+	if err != nil {
+		return buildErr, err
+	}
+
+	// create the steps for the pipeline
+	buildErr, err = c.createSteps(ctx, l)
+	if err != nil {
+		return buildErr, err
+	}
+
+	return nil, nil
+}
+
+// uploadBuild is a helper function to upload the build state
+// with the error that occurred while running the build.
+func (c *client) uploadBuild(b *library.Build, e error) {
+	r := c.repo
+
+	// NOTE: When an error occurs during a build that does not have to do
+	// with a pipeline we should set build status to "error" not "failed"
+	// because it is worker related and not build.
+	if e != nil {
+		b.SetError(e.Error())
+		b.SetStatus(constants.StatusError)
+	}
+
+	c.logger.Info("uploading build state")
+	// send API call to update the build
+	_, _, err := c.Vela.Build.Update(r.GetOrg(), r.GetName(), b)
+	if err != nil {
+		c.logger.Errorf("unable to upload errorred state: %v", err)
+	}
+}
+
+// uploadInit is a helper function to upload the state
+// and the masked logs for the init step.
+func (c *client) uploadInit(init *pipeline.Container, s *library.Step, l *library.Log) {
+	b := c.build
+	r := c.repo
+
+	s.SetFinished(time.Now().UTC().Unix())
+	c.logger.Infof("uploading %s step state", init.Name)
+	// send the step update to the Vela server
+	err := c.updateStep(s)
+	if err != nil {
+		c.logger.Errorf("unable to upload %s state: %v", init.Name, err)
+	}
+
+	// mask the secrets captured in the init output
+	l.SetData(newMasker(secretValues(c.Secrets)).Mask(l.GetData()))
+
+	c.logger.Infof("uploading %s step logs", init.Name)
+	// send API call to update the logs for the step
+	_, _, err = c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), init.Number, l)
+	if err != nil {
+		c.logger.Errorf("unable to upload %s logs: %v", init.Name, err)
+	}
+}
+
+// createInit is a helper function to create and plan the
+// init step for the build.
+func (c *client) createInit(ctx context.Context, ctn *pipeline.Container) (buildErr, err error) {
+	c.logger.Infof("creating %s step", ctn.Name)
+	// create the step
+	err = c.CreateStep(ctx, ctn)
+	if err != nil {
+		return err, fmt.Errorf("unable to create %s step: %w", ctn.Name, err)
+	}
+
+	c.logger.Infof("planning %s step", ctn.Name)
+	// plan the step
+	err = c.PlanStep(ctx, ctn)
+	if err != nil {
+		return err, fmt.Errorf("unable to plan %s step: %w", ctn.Name, err)
+	}
+
+	return nil, nil
+}
+
+// createRuntime is a helper function to create and inspect
+// the runtime network and volume for the pipeline.
+func (c *client) createRuntime(ctx context.Context, l *library.Log) (buildErr, err error) {
+	p := c.pipeline
+
 	c.logger.Info("creating network")
 	// create the runtime network for the pipeline
 	err = c.Runtime.CreateNetwork(ctx, p)
 	if err != nil {
-		e = err
-		return fmt.Errorf("unable to create network: %w", err)
+		return err, fmt.Errorf("unable to create network: %w", err)
 	}
 
 	// update the init log with progress
@@ -168,8 +240,7 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	// inspect the runtime network for the pipeline
 	network, err := c.Runtime.InspectNetwork(ctx, p)
 	if err != nil {
-		e = err
-		return fmt.Errorf("unable to inspect network: %w", err)
+		return err, fmt.Errorf("unable to inspect network: %w", err)
 	}
 
 	// update the init log with network info
@@ -179,8 +250,7 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	// create the runtime volume for the pipeline
 	err = c.Runtime.CreateVolume(ctx, p)
 	if err != nil {
-		e = err
-		return fmt.Errorf("unable to create volume: %w", err)
+		return err, fmt.Errorf("unable to create volume: %w", err)
 	}
 
 	// update the init log with progress
@@ -189,17 +259,23 @@ func (c *client) CreateBuild(ctx context.Context) error {
 	// inspect the runtime volume for the pipeline
 	volume, err := c.Runtime.InspectVolume(ctx, p)
 	if err != nil {
-		e = err
-		return fmt.Errorf("unable to inspect volume: %w", err)
+		return err, fmt.Errorf("unable to inspect volume: %w", err)
 	}
 
 	// update the init log with volume info
 	l.SetData(append(l.GetData(), volume...))
 
+	return nil, nil
+}
+
+// createServices is a helper function to create the
+// services for the pipeline.
+func (c *client) createServices(ctx context.Context, l *library.Log) (buildErr, err error) {
+	p := c.pipeline
+
 	// update the init log with progress
 	l.SetData(append(l.GetData(), []byte("$ Pulling service images...\n")...))
 
-	// create the services for the pipeline
 	for _, s := range p.Services {
 		// TODO: remove this; but we need it for tests
 		s.Detach = true
@@ -218,31 +294,36 @@ func (c *client) CreateBuild(ctx context.Context) error {
 		// create the service
 		err = c.CreateService(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to create %s service: %w", s.Name, err)
+			return err, fmt.Errorf("unable to create %s service: %w", s.Name, err)
 		}
 
 		c.logger.Infof("inspecting %s service", s.Name)
 		// inspect the service image
 		image, err := c.Runtime.InspectImage(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to inspect %s service: %w", s.Name, err)
+			return err, fmt.Errorf("unable to inspect %s service: %w", s.Name, err)
 		}
 
 		// update the init log with service image info
 		l.SetData(append(l.GetData(), image...))
 	}
 
+	return nil, nil
+}
+
+// createStages is a helper function to create the
+// stages for the pipeline.
+func (c *client) createStages(ctx context.Context, l *library.Log) (buildErr, err error) {
+	p := c.pipeline
+
 	// update the init log with progress
 	l.SetData(
 		append(l.GetData(), []byte("$ Pulling stage images...\n")...),
 	)
 
-	// create the stages for the pipeline
 	for _, s := range p.Stages {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the stage is the init stage
+		if c.isInitStage(s) {
 			continue
 		}
 
@@ -250,20 +331,26 @@ func (c *client) CreateBuild(ctx context.Context) error {
 		// create the stage
 		err = c.CreateStage(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to create %s stage: %w", s.Name, err)
+			return err, fmt.Errorf("unable to create %s stage: %w", s.Name, err)
 		}
 	}
 
+	return nil, nil
+}
+
+// createSteps is a helper function to create the
+// steps for the pipeline.
+func (c *client) createSteps(ctx context.Context, l *library.Log) (buildErr, err error) {
+	p := c.pipeline
+
 	// update the init log with progress
 	l.SetData(
 		append(l.GetData(), []byte("$ Pulling step images...\n")...),
 	)
 
-	// create the steps for the pipeline
 	for _, s := range p.Steps {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the step is the init step
+		if c.isInitStep(s) {
 			continue
 		}
 
@@ -280,53 +367,63 @@ func (c *client) CreateBuild(ctx context.Context) error {
 		// create the step
 		err = c.CreateStep(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to create %s step: %w", s.Name, err)
+			return err, fmt.Errorf("unable to create %s step: %w", s.Name, err)
 		}
 
 		c.logger.Infof("inspecting %s step", s.Name)
 		// inspect the step image
 		image, err := c.Runtime.InspectImage(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to inspect %s step: %w", s.Name, err)
+			return err, fmt.Errorf("unable to inspect %s step: %w", s.Name, err)
 		}
 
 		// update the init log with step image info
 		l.SetData(append(l.GetData(), image...))
 	}
 
-	return nil
+	return nil, nil
 }
 
 // ExecBuild runs a pipeline for a build.
 func (c *client) ExecBuild(ctx context.Context) error {
 	b := c.build
 	p := c.pipeline
-	r := c.repo
 	e := c.err
 
 	b.SetStatus(constants.StatusSuccess)
 	c.build = b
 
+	// create a context that is canceled when the build is killed
+	ctx, kill := context.WithCancel(ctx)
+
+	c.mu.Lock()
+	c.kill = kill
+	c.mu.Unlock()
+
 	defer func() {
-		// NOTE: When an error occurs during a build that does not have to do
-		// with a pipeline we should set build status to "error" not "failed"
-		// because it is worker related and not build.
-		if e != nil {
-			b.SetError(e.Error())
-			b.SetStatus(constants.StatusError)
+		c.mu.Lock()
+		c.kill = nil
+		c.mu.Unlock()
+
+		kill()
+	}()
+
+	// batch the step updates while the build is executed
+	flush := c.batchSteps()
+
+	defer func() {
+		// send the step updates remaining in the batch
+		// before the build state is uploaded
+		err := flush()
+		if err != nil && e == nil {
+			e = err
 		}
 
 		// update the build fields
 		b.SetFinished(time.Now().UTC().Unix())
 
-		c.logger.Info("uploading build state")
 		// send API call to update the build
-		_, _, err := c.Vela.Build.Update(r.GetOrg(), r.GetName(), b)
-		if err != nil {
-			c.logger.Errorf("unable to upload errorred state: %v", err)
-		}
+		c.uploadBuild(b, e)
 	}()
 
 	// execute the services for the pipeline
@@ -349,88 +446,147 @@ func (c *client) ExecBuild(ctx context.Context) error {
 	}
 
 	// execute the steps for the pipeline
+	buildErr, err := c.execSteps(ctx)
+	if buildErr != nil {
+		e = buildErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.logger.Debug("waiting for stages completion")
+	// execute the stages and wait for them to complete
+	err = c.execStages(ctx)
+	if err != nil {
+		e = err
+		return fmt.Errorf("unable to wait for stages: %v", err)
+	}
+
+	return nil
+}
+
+// execSteps is a helper function to execute the steps for
+// the pipeline. The error to record on the build is returned
+// separately from the error stopping the build.
+func (c *client) execSteps(ctx context.Context) (buildErr, err error) {
+	p := c.pipeline
+
 	for _, s := range p.Steps {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the step is the init step
+		if c.isInitStep(s) {
 			continue
 		}
 
-		// check if the build status is successful
-		if !strings.EqualFold(b.GetStatus(), constants.StatusSuccess) {
-			// break out of loop to stop running steps
-			break
+		// check if the build context is done
+		if ctx.Err() != nil {
+			// set build status to killed
+			c.setBuildStatus(constants.StatusKilled)
+
+			// mark the remaining steps as killed
+			err := c.killStep(s)
+			if err != nil {
+				c.logger.Errorf("unable to kill %s step: %v", s.Name, err)
+			}
+
+			continue
 		}
 
 		c.logger.Infof("planning %s step", s.Name)
 		// plan the step
 		err := c.PlanStep(ctx, s)
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to plan step: %w", err)
+			return err, fmt.Errorf("unable to plan step: %w", err)
 		}
 
+		// create a context allowing the step to complete during shutdown
+		stepCtx, cancel := c.stepContext(ctx)
+
 		c.logger.Infof("executing %s step", s.Name)
 		// execute the step
-		err = c.ExecStep(ctx, s)
+		err = c.ExecStep(stepCtx, s)
+
+		cancel()
+
+		// check if the build exceeded the timeout
+		if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			c.timeoutBuild(s)
+
+			continue
+		}
+
+		// check if the build was killed
+		if err != nil && c.killedBuild(s) {
+			continue
+		}
+
 		if err != nil {
-			e = err
-			return fmt.Errorf("unable to execute step: %w", err)
+			return err, fmt.Errorf("unable to execute step: %w", err)
 		}
 
 		result, ok := c.steps.Load(s.ID)
 		if !ok {
-			e = err
-			return fmt.Errorf("unable to get step from client")
+			return nil, fmt.Errorf("unable to get step from client")
 		}
 
 		cStep := result.(*library.Step)
 
-		// check the step exit code
-		if s.ExitCode != 0 {
-			// check if we ignore step failures
-			if !s.Ruleset.Continue {
-				// set build status to failure
-				b.SetStatus(constants.StatusFailure)
-			}
-
-			// update the step fields
-			cStep.SetExitCode(s.ExitCode)
-			cStep.SetStatus(constants.StatusFailure)
+		// check the step exit code and status
+		if stepFailed(s, cStep) && !s.Ruleset.Continue {
+			// set build status to failure
+			c.setBuildStatus(constants.StatusFailure)
 		}
 
 		cStep.SetFinished(time.Now().UTC().Unix())
 		c.logger.Infof("uploading %s step state", s.Name)
-		// send API call to update the build
-		_, _, err = c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), cStep)
+		// send the step update to the Vela server
+		err = c.updateStep(cStep)
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
+	return nil, nil
+}
+
+// execStages is a helper function to execute the stages for
+// the pipeline and wait for them to complete.
+func (c *client) execStages(ctx context.Context) error {
+	p := c.pipeline
+
 	// create an error group with the context for each stage
 	stages, stageCtx := errgroup.WithContext(ctx)
 	// create a map to track the progress of each stage
 	stageMap := make(map[string]chan error)
 
+	// create a new channel for each stage in the map
+	//
+	// the map is populated before any stage is executed
+	// since the stages read it to wait on their dependencies
+	for _, s := range p.Stages {
+		// check if the stage is the init stage
+		if c.isInitStage(s) {
+			continue
+		}
+
+		stageMap[s.Name] = make(chan error)
+	}
+
 	// iterate through each stage in the pipeline
 	for _, s := range p.Stages {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the stage is the init stage
+		if c.isInitStage(s) {
 			continue
 		}
 
 		// https://golang.org/doc/faq#closures_and_goroutines
 		stage := s
 
-		// create a new channel for each stage in the map
-		stageMap[stage.Name] = make(chan error)
-
 		stages.Go(func() error {
 			c.logger.Infof("executing %s stage", stage.Name)
 			// execute the stage
 			err := c.ExecStage(stageCtx, stage, stageMap)
 			if err != nil {
-				e = err
 				return fmt.Errorf("unable to execute stage: %w", err)
 			}
 
@@ -438,29 +594,79 @@ func (c *client) ExecBuild(ctx context.Context) error {
 		})
 	}
 
-	c.logger.Debug("waiting for stages completion")
-	// wait for the stages to complete or return an error
-	err := stages.Wait()
+	return stages.Wait()
+}
+
+// timeoutBuild is a helper function to mark the build and the
+// running step as killed once the deadline for the build, set
+// by the caller from the repo or worker timeout, is exceeded.
+func (c *client) timeoutBuild(ctn *pipeline.Container) {
+	c.logger.Errorf("build exceeded timeout while running %s step", ctn.Name)
+
+	// set build status to killed
+	c.mu.Lock()
+	c.build.SetStatus(constants.StatusKilled)
+	c.build.SetError("build exceeded timeout")
+	c.mu.Unlock()
+
+	// mark the running step as killed
+	err := c.killStep(ctn)
 	if err != nil {
-		e = err
-		return fmt.Errorf("unable to wait for stages: %v", err)
+		c.logger.Errorf("unable to kill %s step: %v", ctn.Name, err)
 	}
+}
 
-	return nil
+// setBuildStatus is a helper function to set the status
+// of the build shared by the steps executed concurrently.
+func (c *client) setBuildStatus(status string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.build.SetStatus(status)
+}
+
+// buildStatus is a helper function to capture the status
+// of the build shared by the steps executed concurrently.
+func (c *client) buildStatus() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.build.GetStatus()
+}
+
+// killedBuild is a helper function to check if the build
+// was killed while running the step, marking the running
+// step as killed when it was.
+func (c *client) killedBuild(ctn *pipeline.Container) bool {
+	// check if the build status is killed
+	if !strings.EqualFold(c.buildStatus(), constants.StatusKilled) {
+		return false
+	}
+
+	c.logger.Errorf("build was killed while running %s step", ctn.Name)
+
+	// mark the running step as killed
+	err := c.killStep(ctn)
+	if err != nil {
+		c.logger.Errorf("unable to kill %s step: %v", ctn.Name, err)
+	}
+
+	return true
 }
 
 // DestroyBuild cleans up the build after execution.
 func (c *client) DestroyBuild(ctx context.Context) error {
-	var err error
+	var (
+		err  error
+		errs []error
+	)
 
-	b := c.build
 	p := c.pipeline
-	r := c.repo
 
 	// destroy the steps for the pipeline
 	for _, s := range p.Steps {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the step is the init step
+		if c.isInitStep(s) {
 			continue
 		}
 
@@ -469,13 +675,15 @@ func (c *client) DestroyBuild(ctx context.Context) error {
 		err = c.DestroyStep(ctx, s)
 		if err != nil {
 			c.logger.Errorf("unable to destroy step: %v", err)
+
+			errs = append(errs, fmt.Errorf("unable to destroy %s step: %w", s.Name, err))
 		}
 	}
 
 	// destroy the stages for the pipeline
 	for _, s := range p.Stages {
-		// TODO: remove hardcoded reference
-		if s.Name == "init" {
+		// check if the stage is the init stage
+		if c.isInitStage(s) {
 			continue
 		}
 
@@ -484,6 +692,8 @@ func (c *client) DestroyBuild(ctx context.Context) error {
 		err = c.DestroyStage(ctx, s)
 		if err != nil {
 			c.logger.Errorf("unable to destroy stage: %v", err)
+
+			errs = append(errs, fmt.Errorf("unable to destroy %s stage: %w", s.Name, err))
 		}
 	}
 
@@ -494,23 +704,8 @@ func (c *client) DestroyBuild(ctx context.Context) error {
 		err = c.DestroyService(ctx, s)
 		if err != nil {
 			c.logger.Errorf("unable to destroy service: %v", err)
-		}
 
-		c.logger.Infof("uploading %s service state", s.Name)
-
-		// send API call to update the build
-		result, ok := c.services.Load(s.ID)
-		if !ok {
-			return fmt.Errorf("unable to get service from client")
-		}
-
-		cService := result.(*library.Service)
-		cService.SetExitCode(s.ExitCode)
-		cService.SetFinished(time.Now().UTC().Unix())
-
-		_, _, err = c.Vela.Svc.Update(r.GetOrg(), r.GetName(), b.GetNumber(), cService)
-		if err != nil {
-			c.logger.Errorf("unable to upload service status: %v", err)
+			errs = append(errs, fmt.Errorf("unable to destroy %s service: %w", s.Name, err))
 		}
 	}
 
@@ -519,6 +714,11 @@ func (c *client) DestroyBuild(ctx context.Context) error {
 	err = c.Runtime.RemoveVolume(ctx, p)
 	if err != nil {
 		c.logger.Errorf("unable to remove volume: %v", err)
+
+		errs = append(errs, fmt.Errorf("unable to remove volume: %w", err))
+	} else {
+		// verify the workspace was cleaned from the host
+		c.verifyVolume(ctx, p)
 	}
 
 	c.logger.Info("deleting network")
@@ -526,9 +726,108 @@ func (c *client) DestroyBuild(ctx context.Context) error {
 	err = c.Runtime.RemoveNetwork(ctx, p)
 	if err != nil {
 		c.logger.Errorf("unable to remove network: %v", err)
+
+		errs = append(errs, fmt.Errorf("unable to remove network: %w", err))
+	}
+
+	return combineErrors(errs)
+}
+
+// verifyVolume is a helper function to check the runtime volume
+// for the pipeline no longer exists after it was removed.
+func (c *client) verifyVolume(ctx context.Context, p *pipeline.Build) {
+	// inspect the runtime volume for the pipeline
+	_, err := c.Runtime.InspectVolume(ctx, p)
+	if err != nil {
+		// the volume was not found so the workspace is clean
+		return
 	}
 
-	return err
+	volumeResidual.Inc()
+
+	c.logger.Warnf("volume for pipeline %s still exists after removal", p.ID)
+}
+
+// SummarizeBuild captures the result of the build and the
+// status, exit code and duration of each step planned.
+func (c *client) SummarizeBuild() *executor.BuildSummary {
+	b := c.build
+	p := c.pipeline
+
+	summary := &executor.BuildSummary{
+		Repo:     c.repo.GetFullName(),
+		Number:   b.GetNumber(),
+		Status:   b.GetStatus(),
+		Duration: duration(b.GetStarted(), b.GetFinished()),
+		Steps:    []executor.StepSummary{},
+	}
+
+	// capture the steps for the pipeline and its stages
+	steps := append(pipeline.ContainerSlice{}, p.Steps...)
+	for _, s := range p.Stages {
+		steps = append(steps, s.Steps...)
+	}
+
+	for _, s := range steps {
+		// check if the step is the init step
+		if c.isInitStep(s) {
+			continue
+		}
+
+		// check if the step was planned
+		result, ok := c.steps.Load(s.ID)
+		if !ok {
+			continue
+		}
+
+		step := result.(*library.Step)
+
+		summary.Steps = append(summary.Steps, executor.StepSummary{
+			Name:     step.GetName(),
+			Number:   step.GetNumber(),
+			Status:   step.GetStatus(),
+			ExitCode: step.GetExitCode(),
+			Duration: duration(step.GetStarted(), step.GetFinished()),
+		})
+	}
+
+	return summary
+}
+
+// duration is a helper function to calculate the amount of
+// time between the Unix timestamps, using the current time
+// when the end has not been set.
+func duration(started, finished int64) time.Duration {
+	// check if the start has been set
+	if started == 0 {
+		return 0
+	}
+
+	// check if the end has been set
+	if finished == 0 {
+		finished = time.Now().UTC().Unix()
+	}
+
+	return time.Duration(finished-started) * time.Second
+}
+
+// combineErrors is a helper function to combine the errors
+// captured while destroying resources into a single error.
+func combineErrors(errs []error) error {
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	}
+
+	// capture the message from each error
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+
+	return fmt.Errorf("%d errors occurred: %s", len(errs), strings.Join(msgs, "; "))
 }
 
 // KillBuild kills the current build in execution.
@@ -540,13 +839,19 @@ func (c *client) KillBuild() (*library.Build, error) {
 		return nil, fmt.Errorf("build resource not found")
 	}
 
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the build is in execution
+	if c.kill == nil {
+		return nil, fmt.Errorf("build is not executing")
+	}
+
 	// set the build status to killed
 	b.SetStatus(constants.StatusKilled)
 
-	err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
-	if err != nil {
-		return nil, fmt.Errorf("unable to kill PID: %w", err)
-	}
+	// cancel the context for the build in execution
+	c.kill()
 
 	return b, nil
 }
@@ -5,19 +5,34 @@
 package linux
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/executor"
+	"github.com/go-vela/worker/runtime"
 	"github.com/go-vela/worker/runtime/docker"
 
 	"github.com/go-vela/sdk-go/vela"
 
+	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 
 	"github.com/gin-gonic/gin"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
 )
 
 func TestExecutor_CreateBuild_Success(t *testing.T) {
@@ -224,6 +239,92 @@ func TestExecutor_CreateBuild_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_CreateBuild_MaskInit(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &networkRuntime{Engine: mock, output: []byte("VELA_TOKEN=sup3rs3cr3t\n")}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu  sync.Mutex
+		got []byte
+	)
+
+	handler := server.FakeHandler()
+
+	// return the secret and capture the logs uploaded for the init step
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet && strings.Contains(req.URL.Path, "/secrets/") {
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"name":"token","value":"sup3rs3cr3t"}`))
+
+			return
+		}
+
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1/logs") {
+			body, _ := ioutil.ReadAll(req.Body)
+
+			l := new(library.Log)
+			_ = json.Unmarshal(body, l)
+
+			mu.Lock()
+			got = l.GetData()
+			mu.Unlock()
+
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_init",
+				Environment: map[string]string{},
+				Image:       "#init",
+				Name:        "init",
+				Number:      1,
+			},
+		},
+		Secrets: pipeline.SecretSlice{
+			&pipeline.Secret{
+				Name:   "token",
+				Key:    "github/octocat/token",
+				Engine: "native",
+				Type:   "repo",
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	// run test
+	err := e.CreateBuild(context.Background())
+	if err != nil {
+		t.Errorf("CreateBuild returned err: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if bytes.Contains(got, []byte("sup3rs3cr3t")) {
+		t.Errorf("init logs contain the secret: %s", got)
+	}
+
+	if !bytes.Contains(got, []byte("VELA_TOKEN="+secretMask)) {
+		t.Errorf("init logs are %s, want the secret masked", got)
+	}
+}
+
 func TestExecutor_ExecBuild_Success(t *testing.T) {
 	// setup global vars
 	var (
@@ -430,6 +531,502 @@ func TestExecutor_ExecBuild_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_ExecBuild_Shutdown(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &slowRuntime{Engine: mock, delay: 100 * time.Millisecond}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]string)
+	)
+
+	handler := server.FakeHandler()
+
+	// capture the API calls to update the steps and logs
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			body, _ := ioutil.ReadAll(req.Body)
+
+			mu.Lock()
+			requests[req.URL.Path] = string(body)
+			mu.Unlock()
+
+			req.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithShutdownTimeout(5 * time.Second)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+			&pipeline.Container{
+				ID:          "__0_echo",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "echo",
+				Number:      2,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// cancel the context while the first step is running
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+
+	// run test
+	err := e.ExecBuild(ctx)
+	if err != nil {
+		t.Errorf("ExecBuild returned err: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	_, ok := requests["/api/v1/repos/github/octocat/builds/1/steps/1/logs"]
+	if !ok {
+		t.Errorf("ExecBuild should have uploaded the logs for the running step")
+	}
+
+	if !strings.Contains(requests["/api/v1/repos/github/octocat/builds/1/steps/2"], constants.StatusKilled) {
+		t.Errorf("ExecBuild should have marked the remaining step as killed")
+	}
+
+	if e.build.GetStatus() != constants.StatusKilled {
+		t.Errorf("ExecBuild status is %s, want %s", e.build.GetStatus(), constants.StatusKilled)
+	}
+}
+
+func TestExecutor_ExecBuild_Timeout(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &slowRuntime{Engine: mock, delay: 40 * time.Millisecond}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]string)
+	)
+
+	handler := server.FakeHandler()
+
+	// capture the API calls to update the steps
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut {
+			body, _ := ioutil.ReadAll(req.Body)
+
+			mu.Lock()
+			requests[req.URL.Path] = string(body)
+			mu.Unlock()
+
+			req.Body = ioutil.NopCloser(strings.NewReader(string(body)))
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_one",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "one",
+				Number:      1,
+			},
+			&pipeline.Container{
+				ID:          "__0_two",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "two",
+				Number:      2,
+			},
+			&pipeline.Container{
+				ID:          "__0_three",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "three",
+				Number:      3,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{
+		Org:  vela.String("github"),
+		Name: vela.String("octocat"),
+	})
+
+	// create a context with the deadline for the build
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// run test
+	err := e.ExecBuild(ctx)
+	if err != nil {
+		t.Errorf("ExecBuild returned err: %v", err)
+	}
+
+	if e.build.GetStatus() != constants.StatusKilled {
+		t.Errorf("ExecBuild status is %s, want %s", e.build.GetStatus(), constants.StatusKilled)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if strings.Contains(requests["/api/v1/repos/github/octocat/builds/1/steps/1"], constants.StatusKilled) {
+		t.Errorf("ExecBuild should not have marked the completed step as killed")
+	}
+
+	for _, step := range []string{"2", "3"} {
+		if !strings.Contains(requests["/api/v1/repos/github/octocat/builds/1/steps/"+step], constants.StatusKilled) {
+			t.Errorf("ExecBuild should have marked step %s as killed", step)
+		}
+	}
+}
+
+func TestExecutor_ExecBuild_StepTimeout(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &blockingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithStepTimeout(50 * time.Millisecond)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_sleep",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "sleep",
+				Number:      1,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	// run test
+	err := e.ExecBuild(context.Background())
+	if err != nil {
+		t.Errorf("ExecBuild returned err: %v", err)
+	}
+
+	if e.build.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecBuild status is %s, want %s", e.build.GetStatus(), constants.StatusFailure)
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	step := result.(*library.Step)
+
+	if step.GetStatus() != constants.StatusKilled {
+		t.Errorf("ExecBuild step status is %s, want %s", step.GetStatus(), constants.StatusKilled)
+	}
+}
+
+func TestExecutor_ExecBuild_Failure(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &flakyRuntime{Engine: mock, failures: 1}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_test",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "test",
+				Number:      1,
+			},
+			&pipeline.Container{
+				ID:          "__0_build",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "build",
+				Number:      2,
+			},
+			&pipeline.Container{
+				ID:          "__0_notify",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "notify",
+				Number:      3,
+				Ruleset: pipeline.Ruleset{
+					If: pipeline.Rules{Status: []string{constants.StatusFailure}},
+				},
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	want := map[string]string{
+		"__0_test":   constants.StatusFailure,
+		"__0_build":  statusSkipped,
+		"__0_notify": constants.StatusSuccess,
+	}
+
+	// run test
+	err := e.ExecBuild(context.Background())
+	if err != nil {
+		t.Errorf("ExecBuild returned err: %v", err)
+	}
+
+	if e.build.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecBuild status is %s, want %s", e.build.GetStatus(), constants.StatusFailure)
+	}
+
+	for id, status := range want {
+		result, ok := e.steps.Load(id)
+		if !ok {
+			t.Errorf("ExecBuild did not plan step %s", id)
+
+			continue
+		}
+
+		step := result.(*library.Step)
+
+		if step.GetStatus() != status {
+			t.Errorf("ExecBuild step %s status is %s, want %s", id, step.GetStatus(), status)
+		}
+	}
+}
+
+func TestExecutor_ExecBuild_FailureStep(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &setupRecorder{Engine: &flakyRuntime{Engine: mock, failures: 1}}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_init",
+				Environment: map[string]string{},
+				Image:       "#init",
+				Name:        "init",
+				Number:      1,
+			},
+			&pipeline.Container{
+				ID:          "__0_test",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "test",
+				Number:      2,
+			},
+			&pipeline.Container{
+				ID:          "__0_notify",
+				Commands:    []string{"echo ${MESSAGE}"},
+				Environment: map[string]string{"MESSAGE": "failed"},
+				Image:       "alpine:latest",
+				Name:        "notify",
+				Number:      3,
+				Ruleset: pipeline.Ruleset{
+					If: pipeline.Rules{Status: []string{constants.StatusFailure}},
+				},
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	notify := e.pipeline.Steps[2]
+
+	// run test
+	err := e.CreateBuild(context.Background())
+	if err != nil {
+		t.Errorf("CreateBuild returned err: %v", err)
+	}
+
+	err = e.ExecBuild(context.Background())
+	if err != nil {
+		t.Errorf("ExecBuild returned err: %v", err)
+	}
+
+	if e.build.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecBuild status is %s, want %s", e.build.GetStatus(), constants.StatusFailure)
+	}
+
+	if !r.isSetup(notify.Name) {
+		t.Errorf("CreateBuild did not set up the %s step", notify.Name)
+	}
+
+	if !reflect.DeepEqual(notify.Commands, []string{"echo failed"}) {
+		t.Errorf("CreateBuild commands are %v, want %v", notify.Commands, []string{"echo failed"})
+	}
+
+	result, _ := e.steps.Load(notify.ID)
+	step := result.(*library.Step)
+
+	if step.GetStatus() != constants.StatusSuccess {
+		t.Errorf("ExecBuild %s status is %s, want %s", notify.Name, step.GetStatus(), constants.StatusSuccess)
+	}
+}
+
+func TestExecutor_SummarizeBuild(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &flakyRuntime{Engine: mock, failures: 1}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{
+		Number:   vela.Int(1),
+		Status:   vela.String(constants.StatusRunning),
+		Started:  vela.Int64(1563474077),
+		Finished: vela.Int64(1563474137),
+	})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_init",
+				Environment: map[string]string{},
+				Image:       "#init",
+				Name:        "init",
+				Number:      1,
+			},
+			&pipeline.Container{
+				ID:          "__0_test",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "test",
+				Number:      2,
+			},
+			&pipeline.Container{
+				ID:          "__0_build",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "build",
+				Number:      3,
+			},
+			&pipeline.Container{
+				ID:          "__0_publish",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "publish",
+				Number:      4,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{
+		Org:      vela.String("github"),
+		Name:     vela.String("octocat"),
+		FullName: vela.String("github/octocat"),
+	})
+
+	want := &executor.BuildSummary{
+		Repo:     "github/octocat",
+		Number:   1,
+		Status:   constants.StatusFailure,
+		Duration: time.Minute,
+		Steps: []executor.StepSummary{
+			{Name: "test", Number: 2, Status: constants.StatusFailure, ExitCode: 1},
+			{Name: "build", Number: 3, Status: constants.StatusSuccess, ExitCode: 0},
+		},
+	}
+
+	// run the steps except for the step that is never reached
+	for _, step := range e.pipeline.Steps[:3] {
+		err := e.PlanStep(context.Background(), step)
+		if err != nil {
+			t.Errorf("PlanStep returned err: %v", err)
+		}
+
+		err = e.ExecStep(context.Background(), step)
+		if err != nil {
+			t.Errorf("ExecStep returned err: %v", err)
+		}
+	}
+
+	// mark the build failed once the steps complete
+	e.build.SetStatus(constants.StatusFailure)
+
+	// run test
+	got := e.SummarizeBuild()
+
+	// ignore the durations of the steps
+	for i := range got.Steps {
+		got.Steps[i].Duration = 0
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SummarizeBuild is %+v, want %+v", got, want)
+	}
+}
+
 func TestExecutor_DestroyBuild_Success(t *testing.T) {
 	// setup global vars
 	var (
@@ -639,3 +1236,167 @@ func TestExecutor_DestroyBuild_Success(t *testing.T) {
 		}
 	}
 }
+
+func TestExecutor_DestroyBuild_ResidualVolume(t *testing.T) {
+	// setup types
+	_build := &library.Build{
+		Number: vela.Int(1),
+		Status: vela.String("success"),
+	}
+
+	_repo := &library.Repo{
+		Org:      vela.String("github"),
+		Name:     vela.String("octocat"),
+		FullName: vela.String("github/octocat"),
+	}
+
+	_pipeline := &pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	c, _ := vela.NewClient(s.URL, nil)
+
+	mock, _ := docker.NewMock()
+
+	tests := []struct {
+		name    string
+		residue bool
+		want    int
+	}{
+		{
+			name:    "volume removed",
+			residue: false,
+			want:    0,
+		},
+		{
+			name:    "volume remains",
+			residue: true,
+			want:    1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		logger, hook := logrustest.NewNullLogger()
+
+		r := &volumeRuntime{Engine: mock, residue: test.residue}
+
+		e, _ := New(c, r)
+		e.WithBuild(_build)
+		e.WithPipeline(_pipeline)
+		e.WithRepo(_repo)
+		e.WithLogger(logger)
+
+		err := e.DestroyBuild(context.Background())
+		if err != nil {
+			t.Errorf("%s: DestroyBuild returned err: %v", test.name, err)
+		}
+
+		got := 0
+
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.WarnLevel && strings.Contains(entry.Message, "still exists after removal") {
+				got++
+			}
+		}
+
+		if got != test.want {
+			t.Errorf("%s: DestroyBuild logged %d residual volume warnings, want %d", test.name, got, test.want)
+		}
+	}
+}
+
+// slowRuntime is a runtime that waits on a container
+// for the delay unless the context is done.
+type slowRuntime struct {
+	runtime.Engine
+
+	delay time.Duration
+}
+
+// WaitContainer blocks for the delay or until the context is done.
+func (r *slowRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(r.delay):
+		return nil
+	}
+}
+
+// networkRuntime is a runtime that returns
+// the output for inspecting the network.
+type networkRuntime struct {
+	runtime.Engine
+
+	output []byte
+}
+
+// InspectNetwork returns the output for the network.
+func (r *networkRuntime) InspectNetwork(ctx context.Context, b *pipeline.Build) ([]byte, error) {
+	return r.output, nil
+}
+
+// volumeRuntime is a runtime that reports whether
+// the volume still exists after it was removed.
+type volumeRuntime struct {
+	runtime.Engine
+
+	residue bool
+}
+
+// InspectVolume returns an error unless the volume remains.
+func (r *volumeRuntime) InspectVolume(ctx context.Context, b *pipeline.Build) ([]byte, error) {
+	if !r.residue {
+		return nil, fmt.Errorf("no such volume: %s", b.ID)
+	}
+
+	return []byte(b.ID + "\n"), nil
+}
+
+// setupRecorder is a runtime that records
+// the containers that were set up.
+type setupRecorder struct {
+	runtime.Engine
+
+	mu    sync.Mutex
+	names []string
+}
+
+// SetupContainer records the container and sets it up.
+func (r *setupRecorder) SetupContainer(ctx context.Context, ctn *pipeline.Container) error {
+	r.mu.Lock()
+	r.names = append(r.names, ctn.Name)
+	r.mu.Unlock()
+
+	return r.Engine.SetupContainer(ctx, ctn)
+}
+
+// isSetup returns if the container was set up.
+func (r *setupRecorder) isSetup(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, n := range r.names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,148 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/sirupsen/logrus"
+)
+
+// logCapture captures the masked container output for the
+// log of a step or service and uploads it to the server.
+type logCapture struct {
+	client *client
+	kind   string
+	ctn    *pipeline.Container
+	logger *logrus.Entry
+	update func(*library.Log) (*library.Log, *vela.Response, error)
+
+	mu   sync.Mutex
+	log  *library.Log
+	logs bytes.Buffer
+
+	captured  int
+	truncated bool
+}
+
+// write is a helper function to write the masked line to the
+// buffered logs and upload them once the logs exceed the
+// configured buffer size.
+//
+// Once the logs exceed the configured max size, the line is
+// replaced with a notice and the logs are marked as truncated.
+func (l *logCapture) write(ctx context.Context, line []byte) error {
+	c := l.client
+
+	// check if the line exceeds the max log size
+	if c.LogMaxSize > 0 && l.captured+len(line) > c.LogMaxSize {
+		l.logger.Warnf("truncating logs exceeding max size of %d bytes", c.LogMaxSize)
+
+		l.truncated = true
+		line = []byte(fmt.Sprintf("[truncated: logs exceeded max size of %d bytes]\n", c.LogMaxSize))
+	}
+
+	// send the line to the subscribers as it is scanned
+	if c.LogBroadcaster != nil {
+		c.LogBroadcaster.Broadcast(l.ctn, line)
+	}
+
+	l.captured += len(line)
+
+	l.mu.Lock()
+	// write all the masked logs from the scanner
+	l.logs.Write(line)
+	size := l.logs.Len()
+	l.mu.Unlock()
+
+	// if we have more bytes than the configured buffer size
+	if size > c.LogBufferSize {
+		return l.upload(ctx, false)
+	}
+
+	return nil
+}
+
+// upload is a helper function to append the buffered
+// logs to the log and send them to the server.
+func (l *logCapture) upload(ctx context.Context, force bool) error {
+	c := l.client
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// skip uploading if no new logs have been captured
+	if !force && l.logs.Len() == 0 {
+		return nil
+	}
+
+	l.logger.Trace(l.logs.String())
+
+	// update the existing log with the new bytes
+	l.log.SetData(append(l.log.GetData(), l.logs.Bytes()...))
+
+	l.logger.Debug("appending logs")
+	// send API call to update the logs
+	err := c.retryUpload(ctx, l.logger, func() (*vela.Response, error) {
+		log, resp, err := l.update(l.log)
+		if err != nil {
+			return resp, err
+		}
+
+		l.log = log
+
+		return resp, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// record the uploaded step logs in the metrics
+	if l.kind == "step" {
+		observeLogs(l.ctn, l.logs.Bytes())
+	}
+
+	// send the uploaded logs to the additional sinks
+	for _, sink := range c.LogSinks {
+		err := sink.WriteLogs(l.ctn, l.logs.Bytes())
+		if err != nil {
+			l.logger.Errorf("unable to write logs to sink: %v", err)
+		}
+	}
+
+	// flush the buffer of logs
+	l.logs.Reset()
+
+	return nil
+}
+
+// flush is a helper function to upload the logs captured
+// since the last flush on the interval until done is closed.
+func (l *logCapture) flush(ctx context.Context, interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			// upload the logs captured since the last flush
+			err := l.upload(ctx, false)
+			if err != nil {
+				l.logger.Errorf("unable to flush logs: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// StepHook represents custom logic, like recording
+// metrics or auditing, run around each step executed.
+type StepHook interface {
+	// BeforeStep runs before the step is executed. When an
+	// error is returned, the step is not executed.
+	BeforeStep(ctx context.Context, ctn *pipeline.Container) error
+	// AfterStep runs after the step is executed with the
+	// error returned from executing the step.
+	AfterStep(ctx context.Context, ctn *pipeline.Container, err error)
+}
@@ -0,0 +1,161 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExecutor_ExecStep_Hooks(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &countingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	ctn := &pipeline.Container{
+		ID:          "__0_clone",
+		Environment: map[string]string{},
+		Image:       "target/vela-plugins/git:1",
+		Name:        "clone",
+		Number:      1,
+	}
+
+	var events []string
+
+	first := &recordingHook{name: "first", runtime: r, events: &events}
+	second := &recordingHook{name: "second", runtime: r, events: &events}
+
+	e, _ := New(c, r)
+	e.WithStepHooks(first, second)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{Version: "1", ID: "__0", Steps: pipeline.ContainerSlice{ctn}})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(ctn.ID, new(library.Log))
+	e.steps.Store(ctn.ID, &library.Step{Number: vela.Int(1)})
+
+	want := []string{"first before clone", "second before clone", "first after clone", "second after clone"}
+
+	// run test
+	err := e.ExecStep(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("ExecStep ran hooks %v, want %v", events, want)
+	}
+
+	for _, hook := range []*recordingHook{first, second} {
+		if hook.ctn != ctn {
+			t.Errorf("ExecStep passed %s hook container %v, want %v", hook.name, hook.ctn, ctn)
+		}
+
+		// the container runs after the hooks before the step
+		// and before the hooks after the step
+		if hook.before != 0 || hook.after == 0 {
+			t.Errorf("ExecStep ran %s hook with %d and %d runtime calls, want 0 and more", hook.name, hook.before, hook.after)
+		}
+	}
+}
+
+func TestExecutor_ExecStep_HookFailure(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &countingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	ctn := &pipeline.Container{
+		ID:          "__0_clone",
+		Environment: map[string]string{},
+		Image:       "target/vela-plugins/git:1",
+		Name:        "clone",
+		Number:      1,
+	}
+
+	var events []string
+
+	hook := &recordingHook{name: "audit", runtime: r, events: &events, err: errors.New("audit unavailable")}
+
+	e, _ := New(c, r)
+	e.WithStepHooks(hook)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{Version: "1", ID: "__0", Steps: pipeline.ContainerSlice{ctn}})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(ctn.ID, new(library.Log))
+	e.steps.Store(ctn.ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), ctn)
+	if !errors.Is(err, hook.err) {
+		t.Errorf("ExecStep returned err %v, want %v", err, hook.err)
+	}
+
+	if atomic.LoadInt32(&r.calls) != 0 {
+		t.Errorf("ExecStep made %d runtime calls, want 0", atomic.LoadInt32(&r.calls))
+	}
+
+	if !reflect.DeepEqual(events, []string{"audit before clone"}) {
+		t.Errorf("ExecStep ran hooks %v, want [audit before clone]", events)
+	}
+}
+
+// recordingHook is a step hook that records when it is
+// run and the runtime calls made before it was run.
+type recordingHook struct {
+	name    string
+	runtime *countingRuntime
+	events  *[]string
+	err     error
+
+	ctn    *pipeline.Container
+	before int32
+	after  int32
+}
+
+// BeforeStep records the hook running before the step.
+func (h *recordingHook) BeforeStep(ctx context.Context, ctn *pipeline.Container) error {
+	h.ctn = ctn
+	h.before = atomic.LoadInt32(&h.runtime.calls)
+	*h.events = append(*h.events, h.name+" before "+ctn.Name)
+
+	return h.err
+}
+
+// AfterStep records the hook running after the step.
+func (h *recordingHook) AfterStep(ctx context.Context, ctn *pipeline.Container, err error) {
+	h.after = atomic.LoadInt32(&h.runtime.calls)
+	*h.events = append(*h.events, h.name+" after "+ctn.Name)
+}
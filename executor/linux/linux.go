@@ -5,9 +5,11 @@
 package linux
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"sync"
+	"time"
 
 	"github.com/go-vela/worker/executor"
 
@@ -15,15 +17,170 @@ import (
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 	"github.com/go-vela/worker/runtime"
+	"github.com/go-vela/worker/secret"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// defaultInitStep defines the default name of the
+	// step used to initialize the pipeline.
+	defaultInitStep = "init"
+
+	// defaultLogBufferSize defines the default number of bytes
+	// captured from a container before uploading the logs.
+	defaultLogBufferSize = 1000
+
+	// defaultLogFlushInterval defines the default amount of time
+	// logs captured from a container are buffered before uploading.
+	defaultLogFlushInterval = 5 * time.Second
+
+	// defaultLogLineMaxSize defines the default maximum number of
+	// bytes in a line captured from a container before it is split.
+	defaultLogLineMaxSize = 1024 * 1024
+
+	// defaultLogRetries defines the default number of times
+	// uploading the logs is retried after a transient failure.
+	defaultLogRetries = 3
+
+	// defaultLogRetryBackoff defines the default amount of time
+	// waited before the first retry of uploading the logs.
+	defaultLogRetryBackoff = 500 * time.Millisecond
+
+	// logChunkSize defines the maximum number of bytes read from
+	// a container at a time when capturing the raw output.
+	logChunkSize = 32 * 1024
+
+	// tailRetries defines the number of times tailing a
+	// container is retried while it is not ready after
+	// being started.
+	tailRetries = 5
+
+	// tailRetryBackoff defines the amount of time waited
+	// before the first retry of tailing a container.
+	tailRetryBackoff = 100 * time.Millisecond
+
+	// defaultShutdownTimeout defines the default amount of time
+	// a running step is allowed to complete during shutdown.
+	defaultShutdownTimeout = 30 * time.Second
+
+	// statusSkipped defines the status reported for a
+	// step that is not run since its ruleset did not match.
+	statusSkipped = "skipped"
+
+	// requiredEnv defines the environment variable a step sets
+	// to declare, as a comma separated list, the environment
+	// variables that must be set for the step to be created.
+	//
+	// The pipeline container has no field for the required
+	// environment, so it is declared in the step environment
+	// with a VELA_ prefix like the other variables reserved
+	// by Vela, such as:
+	//
+	//   environment:
+	//     VELA_REQUIRED_ENV: DEPLOY_TOKEN,DEPLOY_TARGET
+	requiredEnv = "VELA_REQUIRED_ENV"
+)
+
 type client struct {
 	Vela     *vela.Client
 	Runtime  runtime.Engine
 	Secrets  map[string]*library.Secret
 	Hostname string
 
+	// DryRun defines if the steps are resolved and logged
+	// without running the containers or uploading the logs.
+	DryRun bool
+	// GlobalEnvironment defines the environment variables
+	// injected into every step. Variables set by the step
+	// take precedence over the global environment.
+	GlobalEnvironment map[string]string
+	// InitStep defines the name of the step used to initialize
+	// the pipeline. The step is not run in a container and
+	// instead captures the output from setting up the build.
+	InitStep string
+	// LenientSubstitution defines if unresolved environment
+	// variables are left intact and substitution failures are
+	// logged as warnings instead of failing the step.
+	LenientSubstitution bool
+	// LogBroadcaster defines the destination the lines captured
+	// from a step container are sent to as they are scanned. A
+	// nil broadcaster will not send the lines.
+	LogBroadcaster LogBroadcaster
+	// LogBufferSize defines the number of bytes captured from
+	// a container before uploading the logs. A value of 0 will
+	// upload the logs for every line captured.
+	LogBufferSize int
+	// LogFlushInterval defines the amount of time logs captured
+	// from a container are buffered before uploading. A value of
+	// 0 will only upload the logs based off the LogBufferSize.
+	LogFlushInterval time.Duration
+	// LogLineMaxSize defines the maximum number of bytes in
+	// a line captured from a step container. Longer lines are
+	// split into multiple lines instead of failing the capture.
+	LogLineMaxSize int
+	// LogMaxSize defines the maximum number of bytes captured
+	// from a step container. Output beyond the limit is dropped
+	// from the step log. A value of 0 will not limit the logs.
+	LogMaxSize int
+	// LogRaw defines if the output captured from a step container
+	// is read in chunks and uploaded as-is instead of scanned into
+	// lines. This preserves binary and non-UTF8 output. Secrets are
+	// still masked, but not when split across two chunks.
+	LogRaw bool
+	// LogRetries defines the number of times uploading the logs
+	// is retried after a transient failure. A value of 0 will
+	// not retry uploading the logs.
+	LogRetries int
+	// LogRetryBackoff defines the amount of time waited before
+	// the first retry of uploading the logs. The backoff is
+	// doubled for every subsequent retry.
+	LogRetryBackoff time.Duration
+	// LogSinks defines the destinations the logs captured from
+	// a container are sent to after uploading to the Vela server.
+	LogSinks []LogSink
+	// LogStripANSI defines if the ANSI escape sequences, like
+	// colors, are removed from each line captured from a step
+	// container before uploading the logs.
+	LogStripANSI bool
+	// LogTimestamps defines if each line captured from a
+	// step container is prefixed with the RFC3339 timestamp
+	// of when the line was captured.
+	LogTimestamps bool
+	// MaxParallelSteps defines the maximum number of step
+	// containers running at once across all stages of the
+	// build. A value of 0 will not limit the steps.
+	MaxParallelSteps int
+	// SecretResolver defines the backend used to resolve the
+	// secrets referenced by a step that were not pulled for
+	// the build. A nil resolver will skip those secrets.
+	SecretResolver secret.Resolver
+	// ShutdownTimeout defines the amount of time a running step
+	// is allowed to complete once the build context is done. A
+	// value of 0 will stop the running step immediately.
+	ShutdownTimeout time.Duration
+	// StepConcurrency defines the number of steps within a stage
+	// executed at once. Steps wait for the steps they need that
+	// are defined before them in the stage. A value of 1 will
+	// execute the steps sequentially.
+	StepConcurrency int
+	// StepHooks defines the custom logic run before
+	// and after each step is executed, in order.
+	StepHooks []StepHook
+	// StepRetries defines the number of times a step container
+	// is run again after it exits with a non-zero exit code
+	// before the step is marked as failed. A value of 0 will
+	// not retry the steps.
+	StepRetries int
+	// StepTimeout defines the maximum amount of time a step
+	// container is allowed to run. A value of 0 will allow the
+	// step to run until the build is complete or timed out.
+	StepTimeout time.Duration
+	// StepUpdateInterval defines the amount of time the status
+	// updates for the steps are batched before being sent to the
+	// Vela server. Updates to the same step within the interval
+	// collapse into one. A value of 0 will send every update.
+	StepUpdateInterval time.Duration
+
 	// private fields
 	logger      *logrus.Entry
 	build       *library.Build
@@ -33,8 +190,15 @@ type client struct {
 	serviceLogs sync.Map
 	steps       sync.Map
 	stepLogs    sync.Map
+	resolved    sync.Map
+	streams     sync.Map
+	stepSlots   chan struct{}
 	user        *library.User
 	err         error
+	kill        context.CancelFunc
+	mu          sync.Mutex
+	updates     map[int]*library.Step
+	updateMu    sync.Mutex
 }
 
 // New returns an Executor implementation that integrates with a Linux instance.
@@ -58,15 +222,24 @@ func New(c *vela.Client, r runtime.Engine) (*client, error) {
 	})
 
 	return &client{
-		Vela:        c,
-		Runtime:     r,
-		Hostname:    h,
-		logger:      l,
-		services:    sync.Map{},
-		serviceLogs: sync.Map{},
-		steps:       sync.Map{},
-		stepLogs:    sync.Map{},
-		err:         nil,
+		Vela:             c,
+		Runtime:          r,
+		Hostname:         h,
+		InitStep:         defaultInitStep,
+		LogBufferSize:    defaultLogBufferSize,
+		LogFlushInterval: defaultLogFlushInterval,
+		LogLineMaxSize:   defaultLogLineMaxSize,
+		LogRetries:       defaultLogRetries,
+		LogRetryBackoff:  defaultLogRetryBackoff,
+		ShutdownTimeout:  defaultShutdownTimeout,
+		StepConcurrency:  1,
+		logger:           l,
+		services:         sync.Map{},
+		serviceLogs:      sync.Map{},
+		steps:            sync.Map{},
+		stepLogs:         sync.Map{},
+		streams:          sync.Map{},
+		err:              nil,
 	}, nil
 }
 
@@ -110,6 +283,269 @@ func (c *client) WithUser(u *library.User) executor.Engine {
 	return c
 }
 
+// WithDryRun sets if the steps are resolved and logged
+// without running the containers in the Engine.
+func (c *client) WithDryRun(dryRun bool) *client {
+	// set dry run in engine
+	c.DryRun = dryRun
+
+	return c
+}
+
+// WithGlobalEnvironment sets the environment
+// variables injected into every step in the Engine.
+func (c *client) WithGlobalEnvironment(env map[string]string) *client {
+	// set global environment in engine if one is provided
+	if env != nil {
+		c.GlobalEnvironment = env
+	}
+
+	return c
+}
+
+// WithInitStep sets the name of the step
+// used to initialize the pipeline in the Engine.
+func (c *client) WithInitStep(name string) *client {
+	// set init step in engine if one is provided
+	if len(name) > 0 {
+		c.InitStep = name
+	}
+
+	return c
+}
+
+// WithLenientSubstitution sets if unresolved environment
+// variables are left intact for the steps in the Engine.
+func (c *client) WithLenientSubstitution(lenient bool) *client {
+	// set lenient substitution in engine
+	c.LenientSubstitution = lenient
+
+	return c
+}
+
+// WithLogger sets the logger used for
+// the executor and runtime output in the Engine.
+func (c *client) WithLogger(l *logrus.Logger) *client {
+	// set logger in engine if one is provided
+	if l != nil {
+		c.logger = l.WithFields(c.logger.Data)
+	}
+
+	return c
+}
+
+// WithLogBroadcaster sets the destination the lines captured
+// from a step container are sent to as they are scanned in the Engine.
+func (c *client) WithLogBroadcaster(broadcaster LogBroadcaster) *client {
+	c.LogBroadcaster = broadcaster
+
+	return c
+}
+
+// WithLogBufferSize sets the number of bytes captured
+// from a container before uploading the logs in the Engine.
+func (c *client) WithLogBufferSize(size int) *client {
+	// set log buffer size in engine if a valid one is provided
+	if size >= 0 {
+		c.LogBufferSize = size
+	}
+
+	return c
+}
+
+// WithLogFlushInterval sets the amount of time logs captured
+// from a container are buffered before uploading in the Engine.
+func (c *client) WithLogFlushInterval(interval time.Duration) *client {
+	// set log flush interval in engine if a valid one is provided
+	if interval >= 0 {
+		c.LogFlushInterval = interval
+	}
+
+	return c
+}
+
+// WithLogLineMaxSize sets the maximum number of bytes in a
+// line captured from a step container in the Engine.
+func (c *client) WithLogLineMaxSize(size int) *client {
+	// set log line max size in engine if a valid one is provided
+	if size > 0 {
+		c.LogLineMaxSize = size
+	}
+
+	return c
+}
+
+// WithLogMaxSize sets the maximum number of bytes
+// captured from a step container in the Engine.
+func (c *client) WithLogMaxSize(size int) *client {
+	// set log max size in engine if a valid one is provided
+	if size >= 0 {
+		c.LogMaxSize = size
+	}
+
+	return c
+}
+
+// WithLogRaw sets if the output captured from a step container
+// is uploaded as raw bytes instead of lines in the Engine.
+func (c *client) WithLogRaw(raw bool) *client {
+	c.LogRaw = raw
+
+	return c
+}
+
+// WithLogRetries sets the number of times uploading
+// the logs is retried after a transient failure in the Engine.
+func (c *client) WithLogRetries(retries int) *client {
+	// set log retries in engine if a valid one is provided
+	if retries >= 0 {
+		c.LogRetries = retries
+	}
+
+	return c
+}
+
+// WithLogRetryBackoff sets the amount of time waited before
+// the first retry of uploading the logs in the Engine.
+func (c *client) WithLogRetryBackoff(backoff time.Duration) *client {
+	// set log retry backoff in engine if a valid one is provided
+	if backoff >= 0 {
+		c.LogRetryBackoff = backoff
+	}
+
+	return c
+}
+
+// WithLogSinks sets the destinations the logs captured
+// from a container are sent to in the Engine.
+func (c *client) WithLogSinks(sinks ...LogSink) *client {
+	c.LogSinks = append(c.LogSinks, sinks...)
+
+	return c
+}
+
+// WithLogStripANSI sets if the ANSI escape sequences are
+// removed from the lines captured from a step container in the Engine.
+func (c *client) WithLogStripANSI(strip bool) *client {
+	c.LogStripANSI = strip
+
+	return c
+}
+
+// WithLogTimestamps sets if each line captured from
+// a step container is prefixed with a timestamp in the Engine.
+func (c *client) WithLogTimestamps(timestamps bool) *client {
+	c.LogTimestamps = timestamps
+
+	return c
+}
+
+// WithMaxParallelSteps sets the maximum number of
+// step containers running at once in the Engine.
+func (c *client) WithMaxParallelSteps(max int) *client {
+	// set max parallel steps in engine if a valid one is provided
+	if max >= 0 {
+		c.MaxParallelSteps = max
+		c.stepSlots = nil
+
+		// create a semaphore with a slot for each step
+		if max > 0 {
+			c.stepSlots = make(chan struct{}, max)
+		}
+	}
+
+	return c
+}
+
+// WithSecretResolver sets the backend used to resolve
+// the secrets referenced by a step in the Engine.
+func (c *client) WithSecretResolver(r secret.Resolver) *client {
+	// set secret resolver in engine if one is provided
+	if r != nil {
+		c.SecretResolver = r
+	}
+
+	return c
+}
+
+// WithShutdownTimeout sets the amount of time a running
+// step is allowed to complete during shutdown in the Engine.
+func (c *client) WithShutdownTimeout(timeout time.Duration) *client {
+	// set shutdown timeout in engine if a valid one is provided
+	if timeout >= 0 {
+		c.ShutdownTimeout = timeout
+	}
+
+	return c
+}
+
+// WithStepConcurrency sets the number of steps within
+// a stage executed at once in the Engine.
+func (c *client) WithStepConcurrency(concurrency int) *client {
+	// set step concurrency in engine if a valid one is provided
+	if concurrency > 0 {
+		c.StepConcurrency = concurrency
+	}
+
+	return c
+}
+
+// WithStepHooks sets the custom logic run
+// around each step executed in the Engine.
+func (c *client) WithStepHooks(hooks ...StepHook) *client {
+	c.StepHooks = append(c.StepHooks, hooks...)
+
+	return c
+}
+
+// WithStepRetries sets the number of times a failed
+// step container is run again in the Engine.
+//
+// The retries apply to every step since the pipeline
+// container has no retries for a step.
+func (c *client) WithStepRetries(retries int) *client {
+	// set step retries in engine if a valid one is provided
+	if retries >= 0 {
+		c.StepRetries = retries
+	}
+
+	return c
+}
+
+// WithStepTimeout sets the maximum amount of
+// time a step container is allowed to run in the Engine.
+func (c *client) WithStepTimeout(timeout time.Duration) *client {
+	// set step timeout in engine if a valid one is provided
+	if timeout >= 0 {
+		c.StepTimeout = timeout
+	}
+
+	return c
+}
+
+// WithStepUpdateInterval sets the amount of time the
+// status updates for the steps are batched in the Engine.
+func (c *client) WithStepUpdateInterval(interval time.Duration) *client {
+	// set step update interval in engine if a valid one is provided
+	if interval >= 0 {
+		c.StepUpdateInterval = interval
+	}
+
+	return c
+}
+
+// isInitStep is a helper function to check
+// if the container is the init step.
+func (c *client) isInitStep(ctn *pipeline.Container) bool {
+	return ctn.Name == c.InitStep
+}
+
+// isInitStage is a helper function to check
+// if the stage is the init stage.
+func (c *client) isInitStage(s *pipeline.Stage) bool {
+	return s.Name == c.InitStep
+}
+
 // GetBuild gets the current build in execution.
 func (c *client) GetBuild() (*library.Build, error) {
 	b := c.build
@@ -5,6 +5,9 @@
 package linux
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"reflect"
 	"testing"
 
@@ -12,6 +15,8 @@ import (
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestLinux_WithBuild(t *testing.T) {
@@ -41,7 +46,7 @@ func TestLinux_WithPipeline(t *testing.T) {
 	vela, _ := vela.NewClient("http://localhost:8080", nil)
 	r, _ := docker.NewMock()
 
-	id := string(1)
+	id := "1"
 	b := &pipeline.Build{ID: id}
 
 	want, _ := New(vela, r)
@@ -170,3 +175,87 @@ func TestLinux_GetRepo(t *testing.T) {
 		t.Errorf("GetPipeline is %v, want %v", got, want)
 	}
 }
+
+func TestLinux_isInitStep(t *testing.T) {
+	// setup types
+	vela, _ := vela.NewClient("http://localhost:8080", nil)
+	r, _ := docker.NewMock()
+
+	// setup tests
+	tests := []struct {
+		initStep string
+		name     string
+		want     bool
+	}{
+		{initStep: "", name: "init", want: true},
+		{initStep: "", name: "clone", want: false},
+		{initStep: "bootstrap", name: "bootstrap", want: true},
+		{initStep: "bootstrap", name: "init", want: false},
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(vela, r)
+		e.WithInitStep(test.initStep)
+
+		got := e.isInitStep(&pipeline.Container{Name: test.name})
+
+		if got != test.want {
+			t.Errorf("isInitStep for %s is %v, want %v", test.name, got, test.want)
+		}
+
+		got = e.isInitStage(&pipeline.Stage{Name: test.name})
+
+		if got != test.want {
+			t.Errorf("isInitStage for %s is %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLinux_WithLogger(t *testing.T) {
+	// setup types
+	vela, _ := vela.NewClient("http://localhost:8080", nil)
+	r, _ := docker.NewMock()
+
+	buf := new(bytes.Buffer)
+
+	l := logrus.New()
+	l.SetOutput(buf)
+	l.SetLevel(logrus.DebugLevel)
+	l.SetFormatter(&logrus.JSONFormatter{})
+
+	e, _ := New(vela, r)
+	e.WithLogger(l)
+
+	// run test
+	err := e.CreateStep(context.Background(), &pipeline.Container{
+		ID:          "__0_clone",
+		Environment: map[string]string{},
+		Image:       "target/vela-plugins/git:1",
+		Name:        "clone",
+		Number:      1,
+	})
+	if err != nil {
+		t.Errorf("CreateStep returned err: %v", err)
+	}
+
+	line, err := buf.ReadBytes('\n')
+	if err != nil {
+		t.Fatalf("unable to read log output: %v", err)
+	}
+
+	got := make(map[string]interface{})
+
+	err = json.Unmarshal(line, &got)
+	if err != nil {
+		t.Errorf("log output is not JSON: %v", err)
+	}
+
+	if got["step"] != "clone" {
+		t.Errorf("log output step is %v, want clone", got["step"])
+	}
+
+	if _, ok := got["host"]; !ok {
+		t.Errorf("log output is missing host field")
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+)
+
+// secretMask defines the value used to replace
+// secrets captured in the container output.
+const secretMask = "***"
+
+// masker replaces the secret values captured
+// in the container output with the secret mask.
+type masker struct {
+	replacer *strings.Replacer
+	values   []string
+}
+
+// newMasker returns a masker built from the secret values.
+//
+// Since the container output is captured line by line,
+// secrets spanning multiple lines are masked per line.
+func newMasker(secrets []string) *masker {
+	var values []string
+
+	// split each secret into the lines it spans
+	for _, secret := range secrets {
+		for _, line := range strings.Split(secret, "\n") {
+			// trim the carriage return from the line
+			line = strings.TrimSuffix(line, "\r")
+
+			// skip lines that only contain whitespace
+			if len(strings.TrimSpace(line)) == 0 {
+				continue
+			}
+
+			values = append(values, line)
+		}
+	}
+
+	// check if no secrets were provided
+	if len(values) == 0 {
+		return &masker{}
+	}
+
+	// sort the values so the longest are replaced first
+	sort.SliceStable(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	// create the pairs of values and masks for the replacer
+	pairs := make([]string, 0, len(values)*2)
+	for _, value := range values {
+		pairs = append(pairs, value, secretMask)
+	}
+
+	return &masker{replacer: strings.NewReplacer(pairs...), values: values}
+}
+
+// Mask replaces the secret values in the line with the secret mask.
+func (m *masker) Mask(line []byte) []byte {
+	// check if there are no secrets to mask
+	if m.replacer == nil {
+		return line
+	}
+
+	return []byte(m.replacer.Replace(string(line)))
+}
+
+// MaskChunk replaces the secret values in the chunk of raw
+// output with the secret mask. The end of the chunk that
+// may be the start of a secret continued in the next chunk
+// is returned to be masked along with the next chunk.
+func (m *masker) MaskChunk(chunk []byte) ([]byte, []byte) {
+	// check if there are no secrets to mask
+	if m.replacer == nil {
+		return chunk, nil
+	}
+
+	// the values are sorted so the first is the longest
+	longest := len(m.values[0])
+
+	masked := make([]byte, 0, len(chunk))
+
+	i := 0
+
+	// scan each position followed by enough output
+	// to contain the longest secret value
+	for i+longest <= len(chunk) {
+		matched := false
+
+		for _, value := range m.values {
+			if bytes.HasPrefix(chunk[i:], []byte(value)) {
+				masked = append(masked, secretMask...)
+				i += len(value)
+				matched = true
+
+				break
+			}
+		}
+
+		if !matched {
+			masked = append(masked, chunk[i])
+			i++
+		}
+	}
+
+	return masked, chunk[i:]
+}
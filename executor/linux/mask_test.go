@@ -0,0 +1,230 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLinux_masker_Mask(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		secrets []string
+		line    string
+		want    string
+	}{
+		{
+			secrets: []string{},
+			line:    "hello world",
+			want:    "hello world",
+		},
+		{
+			secrets: []string{"world"},
+			line:    "hello world",
+			want:    "hello ***",
+		},
+		{
+			secrets: []string{"foo", "foobar"},
+			line:    "foobar and foo",
+			want:    "*** and ***",
+		},
+		{ // first line of a multi-line secret
+			secrets: []string{"-----BEGIN KEY-----\nabc123\n-----END KEY-----\n"},
+			line:    "key: -----BEGIN KEY-----",
+			want:    "key: ***",
+		},
+		{ // partial line of a multi-line secret
+			secrets: []string{"-----BEGIN KEY-----\r\nabc123\r\n-----END KEY-----"},
+			line:    "abc123 -----END KEY-----",
+			want:    "*** ***",
+		},
+		{ // whitespace lines are not masked
+			secrets: []string{"foo\n  \nbar"},
+			line:    "  foo  bar  ",
+			want:    "  ***  ***  ",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := newMasker(test.secrets).Mask([]byte(test.line))
+
+		if string(got) != test.want {
+			t.Errorf("Mask is %s, want %s", got, test.want)
+		}
+	}
+}
+
+func TestLinux_masker_MaskChunk(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		secrets []string
+		chunk   string
+		want    string
+		rest    string
+	}{
+		{
+			secrets: []string{},
+			chunk:   "hello world",
+			want:    "hello world",
+			rest:    "",
+		},
+		{
+			secrets: []string{"world"},
+			chunk:   "hello world and more",
+			want:    "hello *** and ",
+			rest:    "more",
+		},
+		{ // start of a secret held back for the next chunk
+			secrets: []string{"secret"},
+			chunk:   "hello sec",
+			want:    "hell",
+			rest:    "o sec",
+		},
+		{
+			secrets: []string{"foo", "foobar"},
+			chunk:   "foobar and foo!!!!!",
+			want:    "*** and ***",
+			rest:    "!!!!!",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, rest := newMasker(test.secrets).MaskChunk([]byte(test.chunk))
+
+		if string(got) != test.want {
+			t.Errorf("MaskChunk is %q, want %q", got, test.want)
+		}
+
+		if string(rest) != test.rest {
+			t.Errorf("MaskChunk rest is %q, want %q", rest, test.rest)
+		}
+	}
+}
+
+func TestLinux_streamStep_Mask(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	e.Secrets = map[string]*library.Secret{
+		"key": {
+			Name:   vela.String("key"),
+			Value:  vela.String("-----BEGIN KEY-----\nabc123\n-----END KEY-----"),
+			Events: &[]string{"push"},
+		},
+	}
+
+	ctn := &pipeline.Container{
+		ID:          "__0_clone",
+		Environment: map[string]string{"BUILD_EVENT": "push"},
+		Name:        "clone",
+		Number:      1,
+		Secrets: pipeline.StepSecretSlice{
+			&pipeline.StepSecret{
+				Source: "key",
+				Target: "key",
+			},
+		},
+	}
+
+	output := "$ echo $KEY\n-----BEGIN KEY-----\nabc123\n-----END KEY-----\n"
+	want := "$ echo $KEY\n***\n***\n***\n"
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, strings.NewReader(output), l)
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	if string(l.GetData()) != want {
+		t.Errorf("streamStep logs are %q, want %q", l.GetData(), want)
+	}
+}
+
+func TestLinux_streamStep_MaskRaw(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogRaw(true)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	e.Secrets = map[string]*library.Secret{
+		"token": {
+			Name:   vela.String("token"),
+			Value:  vela.String("abc123"),
+			Events: &[]string{"push"},
+		},
+	}
+
+	ctn := &pipeline.Container{
+		ID:          "__0_clone",
+		Environment: map[string]string{"BUILD_EVENT": "push"},
+		Name:        "clone",
+		Number:      1,
+		Secrets: pipeline.StepSecretSlice{
+			&pipeline.StepSecret{
+				Source: "token",
+				Target: "token",
+			},
+		},
+	}
+
+	// place the secret across the boundary of the first chunk
+	padding := strings.Repeat("a", logChunkSize-3)
+	output := padding + "abc123" + "\x00end"
+	want := padding + "***" + "\x00end"
+
+	e.WithLogBufferSize(len(output) * 2)
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, strings.NewReader(output), l)
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	if string(l.GetData()) != want {
+		t.Errorf("streamStep logs end with %q, want %q", l.GetData()[len(padding)-3:], want[len(padding)-3:])
+	}
+}
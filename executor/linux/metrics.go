@@ -0,0 +1,142 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"bytes"
+	"sync"
+	"time"
+
+	"github.com/go-vela/types/constants"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// maxStepLabels defines the maximum number of step names
+// used as label values before the steps are labeled other.
+const maxStepLabels = 100
+
+// Registry is the Prometheus registry for the metrics
+// captured while executing pipelines on the executor.
+//
+// The registry can be served with promhttp.HandlerFor.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// stepLabels captures the step names used as label values.
+	stepLabels   = make(map[string]struct{})
+	stepLabelsMu sync.Mutex
+
+	// stepDuration captures the time spent running a step
+	// container labeled by the step name and status.
+	stepDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: "vela",
+			Subsystem: "worker",
+			Name:      "step_duration_seconds",
+			Help:      "Time spent running a step container.",
+			Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+		},
+		[]string{"step", "status"},
+	)
+
+	// stepTotal captures the number of steps
+	// executed labeled by the step status.
+	stepTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "vela",
+			Subsystem: "worker",
+			Name:      "steps_total",
+			Help:      "Number of step containers executed.",
+		},
+		[]string{"status"},
+	)
+
+	// stepLogBytes captures the number of log
+	// bytes uploaded labeled by the step name.
+	stepLogBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "vela",
+			Subsystem: "worker",
+			Name:      "step_log_bytes_total",
+			Help:      "Number of log bytes uploaded for a step container.",
+		},
+		[]string{"step"},
+	)
+
+	// stepLogLines captures the number of log
+	// lines uploaded labeled by the step name.
+	stepLogLines = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "vela",
+			Subsystem: "worker",
+			Name:      "step_log_lines_total",
+			Help:      "Number of log lines uploaded for a step container.",
+		},
+		[]string{"step"},
+	)
+
+	// volumeResidual captures the number of build
+	// volumes that still existed after being removed.
+	volumeResidual = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Namespace: "vela",
+			Subsystem: "worker",
+			Name:      "volume_residual_total",
+			Help:      "Number of build volumes that still existed after being removed.",
+		},
+	)
+)
+
+func init() {
+	Registry.MustRegister(stepDuration, stepTotal, stepLogBytes, stepLogLines, volumeResidual)
+}
+
+// observeStep is a helper function to record the
+// duration and status of the step in the metrics.
+func observeStep(ctn *pipeline.Container, start time.Time, err error) {
+	status := constants.StatusSuccess
+
+	// check if the step failed to run or exited non-zero
+	if err != nil || ctn.ExitCode != 0 {
+		status = constants.StatusFailure
+	}
+
+	stepDuration.WithLabelValues(stepLabel(ctn.Name), status).Observe(time.Since(start).Seconds())
+	stepTotal.WithLabelValues(status).Inc()
+}
+
+// observeLogs is a helper function to record the
+// bytes and lines uploaded for the step in the metrics.
+func observeLogs(ctn *pipeline.Container, logs []byte) {
+	step := stepLabel(ctn.Name)
+
+	stepLogBytes.WithLabelValues(step).Add(float64(len(logs)))
+	stepLogLines.WithLabelValues(step).Add(float64(bytes.Count(logs, []byte("\n"))))
+}
+
+// stepLabel is a helper function to return the label value for
+// the step name. The step names are set by the pipelines, so only
+// the first maxStepLabels names are used to bound the number of
+// series captured in the metrics.
+func stepLabel(name string) string {
+	stepLabelsMu.Lock()
+	defer stepLabelsMu.Unlock()
+
+	// check if the step name is already used as a label
+	if _, ok := stepLabels[name]; ok {
+		return name
+	}
+
+	// check if the limit of step names is reached
+	if len(stepLabels) >= maxStepLabels {
+		return "other"
+	}
+
+	stepLabels[name] = struct{}{}
+
+	return name
+}
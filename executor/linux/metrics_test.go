@@ -0,0 +1,181 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLinux_Metrics_ExecStep(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_metrics",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "metrics",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, new(library.Step))
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Errorf("Gather returned err: %v", err)
+	}
+
+	var got uint64
+
+	for _, family := range families {
+		if family.GetName() != "vela_worker_step_duration_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "step" && label.GetValue() == "metrics" {
+					got += metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+
+	if got != 1 {
+		t.Errorf("step_duration_seconds observed %d values, want 1", got)
+	}
+}
+
+func TestLinux_Metrics_streamStep(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_log-metrics",
+		Name:   "log-metrics",
+		Number: 1,
+	}
+
+	output := "hello\nworld\n"
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, strings.NewReader(output), new(library.Log))
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Errorf("Gather returned err: %v", err)
+	}
+
+	got := make(map[string]float64)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "step" && label.GetValue() == ctn.Name {
+					got[family.GetName()] += metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+
+	if got["vela_worker_step_log_bytes_total"] != float64(len(output)) {
+		t.Errorf("step_log_bytes_total is %v, want %d", got["vela_worker_step_log_bytes_total"], len(output))
+	}
+
+	if got["vela_worker_step_log_lines_total"] != 2 {
+		t.Errorf("step_log_lines_total is %v, want 2", got["vela_worker_step_log_lines_total"])
+	}
+}
+
+func TestLinux_stepLabel(t *testing.T) {
+	// setup types
+	stepLabelsMu.Lock()
+	labels := stepLabels
+	stepLabels = make(map[string]struct{})
+	stepLabelsMu.Unlock()
+
+	// restore the step names used by the other tests
+	defer func() {
+		stepLabelsMu.Lock()
+		stepLabels = labels
+		stepLabelsMu.Unlock()
+	}()
+
+	for i := 0; i < maxStepLabels; i++ {
+		stepLabel(fmt.Sprintf("step-%d", i))
+	}
+
+	// setup tests
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "step-0", want: "step-0"},
+		{name: fmt.Sprintf("step-%d", maxStepLabels-1), want: fmt.Sprintf("step-%d", maxStepLabels-1)},
+		{name: "unbounded", want: "other"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := stepLabel(test.name)
+
+		if got != test.want {
+			t.Errorf("stepLabel for %s is %s, want %s", test.name, got, test.want)
+		}
+	}
+}
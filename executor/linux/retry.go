@@ -0,0 +1,57 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/sirupsen/logrus"
+)
+
+// retryUpload is a helper function to retry an upload to
+// the Vela server with backoff after a transient failure.
+//
+// Client errors (4xx) are not retried since sending the
+// same request again is not expected to succeed. The
+// retries stop once the context provided is done.
+func (c *client) retryUpload(ctx context.Context, logger *logrus.Entry, upload func() (*vela.Response, error)) error {
+	backoff := c.LogRetryBackoff
+
+	for i := 0; ; i++ {
+		// send the upload to the Vela server
+		resp, err := upload()
+		if err == nil {
+			return nil
+		}
+
+		// check if the error is not retryable
+		if resp != nil && resp.Response != nil &&
+			resp.StatusCode >= http.StatusBadRequest &&
+			resp.StatusCode < http.StatusInternalServerError {
+			return err
+		}
+
+		// check if all retries have been attempted
+		if i >= c.LogRetries {
+			return err
+		}
+
+		logger.Debugf("unable to upload logs: %v. Retrying in %v", err, backoff)
+
+		// wait for the backoff unless the context is done
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+
+		// double the backoff for the next retry
+		backoff *= 2
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLinux_retryUpload(t *testing.T) {
+	// setup types
+	r, _ := docker.NewMock()
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup tests
+	tests := []struct {
+		failure  bool
+		failures int32
+		code     int
+		want     int32
+	}{
+		{ // transient failures recovered by retries
+			failure:  false,
+			failures: 2,
+			code:     http.StatusServiceUnavailable,
+			want:     3,
+		},
+		{ // transient failures exceeding the retries
+			failure:  true,
+			failures: 10,
+			code:     http.StatusInternalServerError,
+			want:     4,
+		},
+		{ // client failures are not retried
+			failure:  true,
+			failures: 1,
+			code:     http.StatusNotFound,
+			want:     1,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var got int32
+
+		s := flakyServer(test.failures, test.code, &got)
+
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithLogRetries(3)
+		e.WithLogRetryBackoff(0)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		err := e.streamStep(context.Background(), ctn, strings.NewReader("hello\n"), new(library.Log))
+
+		s.Close()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("streamStep should have returned err")
+			}
+		} else if err != nil {
+			t.Errorf("streamStep returned err: %v", err)
+		}
+
+		if atomic.LoadInt32(&got) != test.want {
+			t.Errorf("streamStep uploaded %d times, want %d", got, test.want)
+		}
+	}
+}
+
+func TestLinux_retryUpload_Canceled(t *testing.T) {
+	// setup types
+	r, _ := docker.NewMock()
+
+	c, _ := vela.NewClient("http://localhost:8080", nil)
+
+	e, _ := New(c, r)
+	e.WithLogRetries(3)
+	e.WithLogRetryBackoff(time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var got int32
+
+	// run test
+	start := time.Now()
+
+	err := e.retryUpload(ctx, e.logger, func() (*vela.Response, error) {
+		atomic.AddInt32(&got, 1)
+
+		return nil, errors.New("unable to upload logs")
+	})
+	if err == nil {
+		t.Errorf("retryUpload should have returned err")
+	}
+
+	if got != 1 {
+		t.Errorf("retryUpload uploaded %d times, want 1", got)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("retryUpload took %v, want the backoff to stop", time.Since(start))
+	}
+}
+
+// flakyServer is a helper function to create a test server
+// that fails the API calls to update the step logs the
+// provided number of times before succeeding.
+func flakyServer(failures int32, code int, count *int32) *httptest.Server {
+	handler := server.FakeHandler()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/logs") {
+			if atomic.AddInt32(count, 1) <= failures {
+				http.Error(w, `{"error":"unable to update logs"}`, code)
+				return
+			}
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+}
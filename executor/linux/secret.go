@@ -228,3 +228,131 @@ func injectSecrets(ctn *pipeline.Container, m map[string]*library.Secret) error
 
 	return nil
 }
+
+// helper function to capture the values of the secrets injected into the container
+func injectedSecrets(ctn *pipeline.Container, m map[string]*library.Secret) []string {
+	var values []string
+
+	// capture secrets for step
+	for _, secret := range ctn.Secrets {
+		// lookup container secret in map
+		s, ok := m[secret.Source]
+		if !ok {
+			continue
+		}
+
+		// ensure the secret matches with the container
+		if s.Match(ctn) && len(s.GetValue()) > 0 {
+			values = append(values, s.GetValue())
+		}
+	}
+
+	return values
+}
+
+// helper function to capture the values of all secrets for the build
+func secretValues(m map[string]*library.Secret) []string {
+	var values []string
+
+	// capture secrets for build
+	for _, s := range m {
+		if len(s.GetValue()) > 0 {
+			values = append(values, s.GetValue())
+		}
+	}
+
+	return values
+}
+
+// helper function to resolve the secrets for the step that
+// were not pulled for the build from the secret resolver
+func (c *client) resolveSecrets(ctx context.Context, ctn *pipeline.Container) error {
+	// check if a secret resolver is configured
+	if c.SecretResolver == nil {
+		return nil
+	}
+
+	var values []string
+
+	// resolve secrets for step
+	for _, secret := range ctn.Secrets {
+		// skip secrets pulled for the build
+		if _, ok := c.Secrets[secret.Source]; ok {
+			continue
+		}
+
+		// create the path for the secret under the repo
+		path, err := secretPath(c.repo.GetOrg(), c.repo.GetName(), secret.Source)
+		if err != nil {
+			return err
+		}
+
+		logrus.Tracef("resolving secret %s for container %s", path, ctn.Name)
+		// send API call to resolve the secret
+		s, err := c.SecretResolver.Resolve(ctx, path)
+		if err != nil {
+			return fmt.Errorf("unable to resolve secret %s: %w", secret.Source, err)
+		}
+
+		logrus.Tracef("matching secret %s to container %s", path, ctn.Name)
+		// ensure the secret matches with the container
+		if !s.Match(ctn) {
+			continue
+		}
+
+		ctn.Environment[strings.ToUpper(secret.Target)] = s.GetValue()
+
+		values = append(values, s.GetValue())
+	}
+
+	// track the resolved values for masking the step output
+	c.resolved.Store(ctn.ID, values)
+
+	return nil
+}
+
+// helper function to create the path for a secret resolved
+// for the step under the org and repo of the build
+//
+// The source must be a relative path without any "." or
+// ".." segments, optionally followed by #<key>.
+func secretPath(org, repo, source string) (string, error) {
+	// check if the org and repo are provided
+	if len(org) == 0 || len(repo) == 0 {
+		return "", fmt.Errorf("unable to resolve secret %s: no repo provided", source)
+	}
+
+	// split the key from the path of the secret
+	path, key := source, ""
+	if i := strings.LastIndex(source, "#"); i >= 0 {
+		path, key = source[:i], source[i:]
+	}
+
+	// check if the path is absolute
+	if len(path) == 0 || strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("path for secret %s must be relative", source)
+	}
+
+	// check each segment of the path
+	for _, part := range strings.Split(path, "/") {
+		if len(part) == 0 || part == "." || part == ".." || strings.ContainsAny(part, `%?\`) {
+			return "", fmt.Errorf("path for secret %s is invalid", source)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/%s%s", org, repo, path, key), nil
+}
+
+// helper function to capture the values of the secrets
+// injected into the container and resolved for the step
+func (c *client) stepSecrets(ctn *pipeline.Container) []string {
+	values := injectedSecrets(ctn, c.Secrets)
+
+	// capture resolved secrets for step
+	result, ok := c.resolved.Load(ctn.ID)
+	if ok {
+		values = append(values, result.([]string)...)
+	}
+
+	return values
+}
@@ -6,6 +6,7 @@ package linux
 
 import (
 	"context"
+	"fmt"
 	"net/http/httptest"
 	"testing"
 
@@ -309,3 +310,201 @@ func TestLinux_Secret_injectSecret(t *testing.T) {
 		}
 	}
 }
+
+func TestExecutor_CreateStep_SecretScope(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.Secrets = map[string]*library.Secret{
+		"token": {
+			Name:   vela.String("token"),
+			Value:  vela.String("sup3rs3cr3t"),
+			Images: &[]string{"alpine"},
+		},
+	}
+
+	// setup tests
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{image: "alpine:latest", want: "sup3rs3cr3t"},
+		{image: "target/vela-plugins/untrusted:1", want: ""},
+	}
+
+	// run tests
+	for _, test := range tests {
+		ctn := &pipeline.Container{
+			ID:          "__0_publish",
+			Environment: map[string]string{},
+			Image:       test.image,
+			Name:        "publish",
+			Number:      1,
+			Secrets: pipeline.StepSecretSlice{
+				&pipeline.StepSecret{
+					Source: "token",
+					Target: "token",
+				},
+			},
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+		if err != nil {
+			t.Errorf("CreateStep for %s returned err: %v", test.image, err)
+		}
+
+		if ctn.Environment["TOKEN"] != test.want {
+			t.Errorf("TOKEN for %s is %q, want %q", test.image, ctn.Environment["TOKEN"], test.want)
+		}
+	}
+}
+
+func TestExecutor_CreateStep_SecretResolver(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	token := new(library.Secret)
+	token.SetValue("sup3rs3cr3t")
+	token.SetEvents([]string{"push"})
+	token.SetImages([]string{"alpine"})
+
+	secrets := map[string]*library.Secret{
+		"github/octocat/vela/token": token,
+	}
+
+	// setup tests
+	tests := []struct {
+		source  string
+		image   string
+		want    string
+		failure bool
+	}{
+		{source: "vela/token", image: "alpine:latest", want: "sup3rs3cr3t"},
+		{source: "vela/token", image: "golang:latest", want: ""},
+		{source: "vela/notfound", image: "alpine:latest", failure: true},
+		{source: "/github/octocat/vela/token", image: "alpine:latest", failure: true},
+		{source: "../octocat/vela/token", image: "alpine:latest", failure: true},
+		{source: "vela/../../../other/vela/token", image: "alpine:latest", failure: true},
+		{source: "vela/%2e%2e/token", image: "alpine:latest", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(c, r)
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+		e.WithSecretResolver(&fakeResolver{secrets: secrets})
+
+		ctn := &pipeline.Container{
+			ID:          "__0_echo",
+			Environment: map[string]string{"BUILD_EVENT": "push"},
+			Image:       test.image,
+			Name:        "echo",
+			Number:      1,
+			Secrets: pipeline.StepSecretSlice{
+				&pipeline.StepSecret{
+					Source: test.source,
+					Target: "token",
+				},
+			},
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("CreateStep for %s should have returned err", test.source)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("CreateStep for %s returned err: %v", test.source, err)
+		}
+
+		if ctn.Environment["TOKEN"] != test.want {
+			t.Errorf("TOKEN for %s is %v, want %v", test.image, ctn.Environment["TOKEN"], test.want)
+		}
+
+		// the resolved secret is masked in the step output
+		if len(test.want) > 0 {
+			got := newMasker(e.stepSecrets(ctn)).Mask([]byte(test.want))
+			if string(got) != secretMask {
+				t.Errorf("Mask is %s, want %s", got, secretMask)
+			}
+		}
+	}
+}
+
+func TestExecutor_secretPath(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		source  string
+		want    string
+		failure bool
+	}{
+		{source: "vela/token", want: "github/octocat/vela/token"},
+		{source: "vela/token#password", want: "github/octocat/vela/token#password"},
+		{source: "", failure: true},
+		{source: "#password", failure: true},
+		{source: "/secret/vela/token", failure: true},
+		{source: "vela//token", failure: true},
+		{source: "./vela/token", failure: true},
+		{source: "vela/../token", failure: true},
+		{source: "vela/token?version=1", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := secretPath("github", "octocat", test.source)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("secretPath for %q should have returned err", test.source)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("secretPath for %q returned err: %v", test.source, err)
+		}
+
+		if got != test.want {
+			t.Errorf("secretPath for %q is %v, want %v", test.source, got, test.want)
+		}
+	}
+}
+
+// fakeResolver is a secret resolver that
+// returns the secrets from the map.
+type fakeResolver struct {
+	secrets map[string]*library.Secret
+}
+
+// Resolve returns the secret from the map.
+func (r *fakeResolver) Resolve(ctx context.Context, ref string) (*library.Secret, error) {
+	s, ok := r.secrets[ref]
+	if !ok {
+		return nil, fmt.Errorf("secret %s not found", ref)
+	}
+
+	return s, nil
+}
@@ -5,16 +5,21 @@
 package linux
 
 import (
-	"bufio"
-	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"time"
 
+	"github.com/go-vela/worker/runtime"
+
+	"github.com/go-vela/sdk-go/vela"
+
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 )
 
 // CreateService prepares the service for execution.
@@ -36,7 +41,10 @@ func (c *client) CreateService(ctx context.Context, ctn *pipeline.Container) err
 
 // PlanService defines a function that prepares the service for execution.
 func (c *client) PlanService(ctx context.Context, ctn *pipeline.Container) error {
-	var err error
+	var (
+		service *library.Service
+		log     *library.Log
+	)
 
 	b := c.build
 	r := c.repo
@@ -50,40 +58,57 @@ func (c *client) PlanService(ctx context.Context, ctn *pipeline.Container) error
 	s := new(library.Service)
 	s.SetName(ctn.Name)
 	s.SetNumber(ctn.Number)
-	s.SetStatus(constants.StatusRunning)
-	s.SetStarted(time.Now().UTC().Unix())
+	s.SetStatus(constants.StatusPending)
 
-	logger.Debug("uploading service state")
-	// send API call to update the service
-	s, _, err = c.Vela.Svc.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
-	if err != nil {
-		return err
-	}
+	// create an error group to send the API calls concurrently
+	calls := new(errgroup.Group)
 
-	s.SetStatus(constants.StatusSuccess)
+	calls.Go(func() error {
+		logger.Debug("uploading service state")
+		// send API call to update the service
+		result, _, err := c.Vela.Svc.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
+		if err != nil {
+			return fmt.Errorf("unable to upload service state: %w", err)
+		}
 
-	// add a service to a map
-	c.services.Store(ctn.ID, s)
+		service = result
 
-	// get the service log here
-	logger.Debug("retrieve service log")
-	// send API call to capture the service log
-	l, _, err := c.Vela.Log.GetService(r.GetOrg(), r.GetName(), b.GetNumber(), s.GetNumber())
+		return nil
+	})
+
+	calls.Go(func() error {
+		logger.Debug("retrieve service log")
+		// send API call to capture the service log
+		result, _, err := c.Vela.Log.GetService(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve service log: %w", err)
+		}
+
+		log = result
+
+		return nil
+	})
+
+	// wait for the API calls to complete
+	err := calls.Wait()
 	if err != nil {
 		return err
 	}
 
-	// add a step log to a map
-	c.serviceLogs.Store(ctn.ID, l)
+	// add a service to a map
+	c.services.Store(ctn.ID, service)
+
+	// add a service log to a map
+	c.serviceLogs.Store(ctn.ID, log)
 
 	return nil
 }
 
 // ExecService runs a service.
+//
+// Services run for the duration of the build, so the
+// service is not waited on after the container starts.
 func (c *client) ExecService(ctx context.Context, ctn *pipeline.Container) error {
-	b := c.build
-	r := c.repo
-
 	result, ok := c.serviceLogs.Load(ctn.ID)
 	if !ok {
 		return fmt.Errorf("unable to get service log from client")
@@ -96,79 +121,118 @@ func (c *client) ExecService(ctx context.Context, ctn *pipeline.Container) error
 		"service": ctn.Name,
 	})
 
+	logger.Debug("starting service")
+	// report the service as running
+	err := c.startService(ctn)
+	if err != nil {
+		return err
+	}
+
 	logger.Debug("running container")
 	// run the runtime container
-	err := c.Runtime.RunContainer(ctx, c.pipeline, ctn)
+	err = c.Runtime.RunContainer(ctx, c.pipeline, ctn)
 	if err != nil {
 		return err
 	}
 
-	// create new buffer for uploading logs
-	logs := new(bytes.Buffer)
-	go func() error {
+	// track the logs streaming from the container so they
+	// are captured when the service is destroyed
+	logs := newStream()
+	c.streams.Store(ctn.ID, logs)
+
+	go func() {
 		logger.Debug("tailing container")
 		// tail the runtime container
-		rc, err := c.Runtime.TailContainer(ctx, ctn)
+		rc, err := c.tailContainer(ctx, ctn)
 		if err != nil {
-			return err
+			logs.finish(err)
+			return
 		}
 		defer rc.Close()
 
-		// create new scanner from the container output
-		scanner := bufio.NewScanner(rc)
+		// stream the container output to the service log
+		logs.finish(c.streamService(ctx, ctn, rc, l))
+	}()
 
-		// scan entire container output
-		for scanner.Scan() {
-			// write all the logs from the scanner
-			logs.Write(append(scanner.Bytes(), []byte("\n")...))
+	return nil
+}
 
-			// if we have at least 1000 bytes in our buffer
-			if logs.Len() > 1000 {
-				logger.Trace(logs.String())
+// streamService is a helper function to capture the container
+// output from the reader and upload it to the service log.
+func (c *client) streamService(ctx context.Context, ctn *pipeline.Container, rc io.Reader, l *library.Log) error {
+	b := c.build
+	r := c.repo
 
-				// update the existing log with the new bytes
-				l.SetData(append(l.GetData(), logs.Bytes()...))
+	return c.streamLogs(ctx, "service", ctn, rc, l, func(l *library.Log) (*library.Log, *vela.Response, error) {
+		// send API call to update the logs for the service
+		return c.Vela.Log.UpdateService(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
+	})
+}
 
-				logger.Debug("appending logs")
-				l, _, err = c.Vela.Log.UpdateService(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
-				if err != nil {
-					return err
-				}
+// startService is a helper function to report the
+// planned service as running before it is executed.
+func (c *client) startService(ctn *pipeline.Container) error {
+	b := c.build
+	r := c.repo
 
-				// flush the buffer of logs
-				logs.Reset()
-			}
-		}
-		logger.Trace(logs.String())
+	result, ok := c.services.Load(ctn.ID)
+	if !ok {
+		return fmt.Errorf("unable to get service from client")
+	}
 
-		// update the existing log with the last bytes
-		l.SetData(append(l.GetData(), logs.Bytes()...))
+	s := result.(*library.Service)
 
-		logger.Debug("uploading logs")
-		// send API call to update the logs for the service
-		l, _, err = c.Vela.Log.UpdateService(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
-		if err != nil {
-			return err
-		}
+	// update the service fields for the running service
+	s.SetStatus(constants.StatusRunning)
+	s.SetStarted(time.Now().UTC().Unix())
 
-		return nil
-	}()
+	c.logger.Infof("uploading %s service running state", ctn.Name)
+	// send API call to update the service
+	_, _, err := c.Vela.Svc.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
+	if err != nil {
+		return err
+	}
 
-	// do not wait for detached containers
-	if ctn.Detach {
-		return nil
+	return nil
+}
+
+// reportService is a helper function to update the service status
+// in the API from the container exit code and the runtime error.
+func (c *client) reportService(ctn *pipeline.Container, svcErr error) error {
+	b := c.build
+	r := c.repo
+
+	result, ok := c.services.Load(ctn.ID)
+	if !ok {
+		return fmt.Errorf("unable to get service from client")
 	}
 
-	logger.Debug("waiting for container")
-	// wait for the runtime container
-	err = c.Runtime.WaitContainer(ctx, ctn)
-	if err != nil {
-		return err
+	s := result.(*library.Service)
+
+	// update the service fields from the exit code
+	s.SetExitCode(ctn.ExitCode)
+	s.SetFinished(time.Now().UTC().Unix())
+	s.SetStatus(constants.StatusSuccess)
+
+	// check the service exit code
+	if ctn.ExitCode != 0 {
+		s.SetStatus(constants.StatusFailure)
 	}
 
-	logger.Debug("inspecting container")
-	// inspect the runtime container
-	err = c.Runtime.InspectContainer(ctx, ctn)
+	// check if the service failed in the runtime
+	if svcErr != nil {
+		s.SetError(svcErr.Error())
+		s.SetStatus(constants.StatusFailure)
+	}
+
+	// check if the service was killed in the runtime
+	if errors.Is(svcErr, runtime.ErrKilled) {
+		s.SetStatus(constants.StatusKilled)
+	}
+
+	c.logger.Infof("uploading %s service state", ctn.Name)
+	// send API call to update the service
+	_, _, err := c.Vela.Svc.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
 	if err != nil {
 		return err
 	}
@@ -177,6 +241,10 @@ func (c *client) ExecService(ctx context.Context, ctn *pipeline.Container) error
 }
 
 // DestroyService cleans up services after execution.
+//
+// The service state is uploaded with the exit code of the
+// container once the container is removed and the logs
+// have finished uploading.
 func (c *client) DestroyService(ctx context.Context, ctn *pipeline.Container) error {
 	// update engine logger with extra metadata
 	logger := c.logger.WithFields(logrus.Fields{
@@ -185,17 +253,40 @@ func (c *client) DestroyService(ctx context.Context, ctn *pipeline.Container) er
 
 	logger.Debug("inspecting container")
 	// inspect the runtime container
-	err := c.Runtime.InspectContainer(ctx, ctn)
-	if err != nil {
-		return err
+	svcErr := c.Runtime.InspectContainer(ctx, ctn)
+
+	// remove the container even if it failed to be inspected
+	if svcErr != nil {
+		logger.Errorf("unable to inspect %s service: %v", ctn.Name, svcErr)
 	}
 
 	logger.Debug("removing container")
 	// remove the runtime container
-	err = c.Runtime.RemoveContainer(ctx, ctn)
+	err := c.Runtime.RemoveContainer(ctx, ctn)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	// check if logs were streamed from the container
+	result, ok := c.streams.Load(ctn.ID)
+	if ok {
+		c.streams.Delete(ctn.ID)
+
+		logger.Debug("waiting for logs")
+		// wait for the container logs to finish uploading
+		err = result.(*stream).wait(ctx)
+		if err != nil {
+			logger.Errorf("unable to stream logs for %s service: %v", ctn.Name, err)
+		}
+	}
+
+	// check if the service was planned
+	_, ok = c.services.Load(ctn.ID)
+	if !ok {
+		return nil
+	}
+
+	logger.Debug("reporting exit code")
+	// report the container exit code for the service
+	return c.reportService(ctn, svcErr)
 }
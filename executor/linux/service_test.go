@@ -5,15 +5,25 @@
 package linux
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-vela/mock/server"
 	"github.com/go-vela/sdk-go/vela"
+	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
+	"github.com/go-vela/worker/runtime"
 	"github.com/go-vela/worker/runtime/docker"
 )
 
@@ -237,3 +247,263 @@ func TestExecutor_DestroyService_Success(t *testing.T) {
 		t.Errorf("DestroyService is %v, want nil", got)
 	}
 }
+
+func TestExecutor_DestroyService_Killed(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Services: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "service_org_repo_0_killed",
+				Environment: map[string]string{},
+				Image:       "postgres:11-alpine",
+				Name:        "killed",
+				Ports:       []string{"5432:5432"},
+			},
+		},
+	})
+
+	// run test
+	got := e.DestroyService(context.Background(), e.pipeline.Services[0])
+
+	if got != nil {
+		t.Errorf("DestroyService is %v, want nil", got)
+	}
+}
+
+func TestExecutor_DestroyService_InspectFailure(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &inspectRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+
+	ctn := &pipeline.Container{
+		ID:          "service_org_repo_0_postgres",
+		Environment: map[string]string{},
+		Image:       "postgres:11-alpine",
+		Name:        "postgres",
+	}
+
+	// run test
+	got := e.DestroyService(context.Background(), ctn)
+
+	if got != nil {
+		t.Errorf("DestroyService is %v, want nil", got)
+	}
+
+	if r.removed != 1 {
+		t.Errorf("DestroyService removed %d containers, want 1", r.removed)
+	}
+}
+
+func TestExecutor_streamService(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	got := new(bytes.Buffer)
+
+	e, _ := New(c, r)
+	e.WithLogSinks(&fakeSink{buf: got})
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	e.Secrets = map[string]*library.Secret{
+		"password": {
+			Name:   vela.String("password"),
+			Value:  vela.String("sup3rs3cr3t"),
+			Events: &[]string{"push"},
+		},
+	}
+
+	ctn := &pipeline.Container{
+		ID:          "service_org_repo_0_postgres",
+		Environment: map[string]string{"BUILD_EVENT": "push"},
+		Image:       "postgres:11-alpine",
+		Name:        "postgres",
+		Number:      1,
+		Secrets: pipeline.StepSecretSlice{
+			&pipeline.StepSecret{
+				Source: "password",
+				Target: "postgres_password",
+			},
+		},
+	}
+
+	output := "starting with password sup3rs3cr3t\nready\n"
+	want := "starting with password ***\nready\n"
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamService(context.Background(), ctn, strings.NewReader(output), l)
+	if err != nil {
+		t.Errorf("streamService returned err: %v", err)
+	}
+
+	if string(l.GetData()) != want {
+		t.Errorf("streamService logs are %q, want %q", l.GetData(), want)
+	}
+
+	if got.String() != want {
+		t.Errorf("LogSink received %q, want %q", got.String(), want)
+	}
+}
+
+func TestExecutor_Service_Lifecycle(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup tests
+	tests := []struct {
+		id   string
+		want []string
+	}{
+		{
+			id:   "service_org_repo_0_postgres",
+			want: []string{constants.StatusPending, constants.StatusRunning, constants.StatusSuccess},
+		},
+		{
+			id:   "service_org_repo_0_killed",
+			want: []string{constants.StatusPending, constants.StatusRunning, constants.StatusKilled},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var (
+			mu       sync.Mutex
+			statuses []string
+			finished int64
+		)
+
+		handler := server.FakeHandler()
+
+		// capture the status sent by the API calls to update the service
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/services/1") {
+				body, _ := ioutil.ReadAll(req.Body)
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+				svc := new(library.Service)
+
+				err := json.Unmarshal(body, svc)
+				if err != nil {
+					t.Errorf("unable to unmarshal service: %v", err)
+				}
+
+				mu.Lock()
+				statuses = append(statuses, svc.GetStatus())
+				finished = svc.GetFinished()
+				mu.Unlock()
+			}
+
+			handler.ServeHTTP(w, req)
+		}))
+
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithPipeline(&pipeline.Build{
+			Version: "1",
+			ID:      "__0",
+			Services: pipeline.ContainerSlice{
+				&pipeline.Container{
+					ID:          test.id,
+					Detach:      true,
+					Environment: map[string]string{},
+					Image:       "postgres:11-alpine",
+					Name:        "postgres",
+					Number:      1,
+					Ports:       []string{"5432:5432"},
+				},
+			},
+		})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		err := e.PlanService(context.Background(), e.pipeline.Services[0])
+		if err != nil {
+			t.Errorf("PlanService for %s returned err: %v", test.id, err)
+		}
+
+		err = e.ExecService(context.Background(), e.pipeline.Services[0])
+		if err != nil {
+			t.Errorf("ExecService for %s returned err: %v", test.id, err)
+		}
+
+		err = e.DestroyService(context.Background(), e.pipeline.Services[0])
+		if err != nil {
+			t.Errorf("DestroyService for %s returned err: %v", test.id, err)
+		}
+
+		mu.Lock()
+
+		if !reflect.DeepEqual(statuses, test.want) {
+			t.Errorf("Service statuses for %s are %v, want %v", test.id, statuses, test.want)
+		}
+
+		if finished == 0 {
+			t.Errorf("Service for %s should have been reported as finished", test.id)
+		}
+
+		mu.Unlock()
+
+		if _, ok := e.streams.Load(test.id); ok {
+			t.Errorf("DestroyService for %s should have waited on the logs", test.id)
+		}
+
+		s.Close()
+	}
+}
+
+// inspectRuntime is a runtime that fails to
+// inspect containers and counts the removals.
+type inspectRuntime struct {
+	runtime.Engine
+
+	removed int
+}
+
+// InspectContainer returns an error for every container.
+func (r *inspectRuntime) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
+	return errors.New("unable to inspect container")
+}
+
+// RemoveContainer counts the containers removed.
+func (r *inspectRuntime) RemoveContainer(ctx context.Context, ctn *pipeline.Container) error {
+	r.removed++
+
+	return r.Engine.RemoveContainer(ctx, ctn)
+}
@@ -0,0 +1,42 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"io"
+	"sync"
+
+	"github.com/go-vela/types/pipeline"
+)
+
+// LogSink represents a destination the logs captured from
+// a step are sent to in addition to the Vela server.
+type LogSink interface {
+	// WriteLogs sends the logs captured from the step to the sink.
+	WriteLogs(ctn *pipeline.Container, logs []byte) error
+}
+
+type writerSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriterSink returns a LogSink implementation that
+// writes the logs captured from a step to the writer.
+//
+// This can be used to send the logs to standard output or a file.
+func NewWriterSink(w io.Writer) LogSink {
+	return &writerSink{w: w}
+}
+
+// WriteLogs writes the logs captured from the step to the writer.
+func (s *writerSink) WriteLogs(ctn *pipeline.Container, logs []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err := s.w.Write(logs)
+
+	return err
+}
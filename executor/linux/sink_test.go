@@ -0,0 +1,105 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package linux
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime/docker"
+
+	"github.com/go-vela/sdk-go/vela"
+
+	"github.com/go-vela/types/library"
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestLinux_streamStep_LogSinks(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	output := "hello\nworld\n"
+
+	got := new(bytes.Buffer)
+	sink := &fakeSink{buf: got}
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(0)
+	e.WithLogSinks(sink, &fakeSink{err: errors.New("sink unavailable")})
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, strings.NewReader(output), new(library.Log))
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	if got.String() != output {
+		t.Errorf("LogSink received %q, want %q", got.String(), output)
+	}
+
+	if sink.step != ctn.Name {
+		t.Errorf("LogSink received logs for step %s, want %s", sink.step, ctn.Name)
+	}
+}
+
+func TestLinux_NewWriterSink(t *testing.T) {
+	// setup types
+	got := new(bytes.Buffer)
+	want := "hello\n"
+
+	// run test
+	err := NewWriterSink(got).WriteLogs(&pipeline.Container{Name: "clone"}, []byte(want))
+	if err != nil {
+		t.Errorf("WriteLogs returned err: %v", err)
+	}
+
+	if got.String() != want {
+		t.Errorf("WriteLogs wrote %q, want %q", got.String(), want)
+	}
+}
+
+// fakeSink is a log sink that captures the
+// logs written to it for the step.
+type fakeSink struct {
+	buf  *bytes.Buffer
+	step string
+	err  error
+}
+
+// WriteLogs captures the logs for the step.
+func (s *fakeSink) WriteLogs(ctn *pipeline.Container, logs []byte) error {
+	if s.err != nil {
+		return s.err
+	}
+
+	s.step = ctn.Name
+	s.buf.Write(logs)
+
+	return nil
+}
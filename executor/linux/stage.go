@@ -6,13 +6,16 @@ package linux
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 )
 
 // CreateStage prepares the stage for execution.
@@ -78,9 +81,6 @@ func (c *client) PlanStage(ctx context.Context, s *pipeline.Stage) error {
 
 // ExecStage runs a stage.
 func (c *client) ExecStage(ctx context.Context, s *pipeline.Stage, m map[string]chan error) error {
-	b := c.build
-	r := c.repo
-
 	// update engine logger with extra metadata
 	logger := c.logger.WithFields(logrus.Fields{
 		"stage": s.Name,
@@ -115,54 +115,185 @@ func (c *client) ExecStage(ctx context.Context, s *pipeline.Stage, m map[string]
 	defer close(m[s.Name])
 
 	logger.Debug("starting execution of stage")
+
+	// check if the steps for the stage run concurrently
+	if c.StepConcurrency > 1 {
+		return c.execStageParallel(ctx, s, logger)
+	}
+
 	// execute the steps for the stage
 	for _, step := range s.Steps {
-		c.logger.Infof("planning %s step", step.Name)
-		// plan the step
-		err := c.PlanStep(ctx, step)
-		if err != nil {
-			return fmt.Errorf("unable to plan step %s: %w", step.Name, err)
-		}
-
-		logger.Debugf("executing %s step", step.Name)
-		// execute the step
-		err = c.ExecStep(ctx, step)
+		err := c.execStageStep(ctx, step, logger)
 		if err != nil {
 			return err
 		}
+	}
+
+	return nil
+}
+
+// execStageParallel is a helper function to execute the steps
+// for the stage concurrently up to the step concurrency.
+//
+// A step waits for the steps it needs that are defined before
+// it in the stage. Once a step fails, the steps that have not
+// started are planned and skipped unless their ruleset matches
+// the failed build, the same as the steps executed in order.
+// Once a step returns an error, the steps that have not
+// started are planned and skipped.
+func (c *client) execStageParallel(ctx context.Context, s *pipeline.Stage, logger *logrus.Entry) error {
+	var (
+		mu      sync.Mutex
+		errored bool
+	)
+
+	// create a channel for each step closed when the step completes
+	done := make(map[string]chan struct{})
+
+	// create a semaphore to limit the steps running at once
+	sem := make(chan struct{}, c.StepConcurrency)
 
-		result, ok := c.steps.Load(step.ID)
-		if !ok {
-			return fmt.Errorf("unable to get step from client")
+	// create an error group to execute the steps concurrently
+	steps := new(errgroup.Group)
+
+	for _, step := range s.Steps {
+		step := step
+
+		// capture the steps defined before this step it needs
+		var needs []chan struct{}
+
+		for _, name := range step.Needs {
+			if ch, ok := done[name]; ok {
+				needs = append(needs, ch)
+			}
 		}
 
-		cStep := result.(*library.Step)
+		done[step.Name] = make(chan struct{})
+		complete := done[step.Name]
+
+		steps.Go(func() error {
+			// release the steps that need this step
+			defer close(complete)
 
-		// check the step exit code
-		if step.ExitCode != 0 {
-			// check if we ignore step failures
-			if !step.Ruleset.Continue {
-				// set build status to failure
-				b.SetStatus(constants.StatusFailure)
+			// wait for the steps this step needs to complete
+			for _, ch := range needs {
+				<-ch
 			}
 
-			// update the step fields
-			cStep.SetExitCode(step.ExitCode)
-			cStep.SetStatus(constants.StatusFailure)
-		}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			mu.Lock()
+			skip := errored
+			mu.Unlock()
+
+			// check if another step in the stage returned an error
+			if skip {
+				return c.skipStageStep(ctx, step)
+			}
+
+			// plan and execute the step, which is skipped by
+			// its ruleset if another step failed the build
+			err := c.execStageStep(ctx, step, logger)
+			if err != nil {
+				mu.Lock()
+				errored = true
+				mu.Unlock()
+			}
 
-		cStep.SetFinished(time.Now().UTC().Unix())
-		c.logger.Infof("uploading %s step state", step.Name)
-		// send API call to update the build
-		_, _, err = c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), cStep)
-		if err != nil {
 			return err
+		})
+	}
+
+	return steps.Wait()
+}
+
+// execStageStep is a helper function to plan, execute
+// and upload the final state of a step in the stage.
+func (c *client) execStageStep(ctx context.Context, step *pipeline.Container, logger *logrus.Entry) error {
+	// check if the build context is done
+	if ctx.Err() != nil {
+		// set build status to killed
+		c.setBuildStatus(constants.StatusKilled)
+
+		// mark the remaining steps as killed
+		err := c.killStep(step)
+		if err != nil {
+			logger.Errorf("unable to kill %s step: %v", step.Name, err)
 		}
+
+		return nil
+	}
+
+	c.logger.Infof("planning %s step", step.Name)
+	// plan the step
+	err := c.PlanStep(ctx, step)
+	if err != nil {
+		return fmt.Errorf("unable to plan step %s: %w", step.Name, err)
+	}
+
+	// create a context allowing the step to complete during shutdown
+	stepCtx, cancel := c.stepContext(ctx)
+
+	logger.Debugf("executing %s step", step.Name)
+	// execute the step
+	err = c.ExecStep(stepCtx, step)
+
+	cancel()
+
+	// check if the build exceeded the timeout
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		c.timeoutBuild(step)
+
+		return nil
+	}
+
+	// check if the build was killed
+	if err != nil && c.killedBuild(step) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	result, ok := c.steps.Load(step.ID)
+	if !ok {
+		return fmt.Errorf("unable to get step from client")
+	}
+
+	cStep := result.(*library.Step)
+
+	// check the step exit code and status
+	if stepFailed(step, cStep) && !step.Ruleset.Continue {
+		// set build status to failure
+		c.setBuildStatus(constants.StatusFailure)
+	}
+
+	cStep.SetFinished(time.Now().UTC().Unix())
+	c.logger.Infof("uploading %s step state", step.Name)
+	// send the step update to the Vela server
+	err = c.updateStep(cStep)
+	if err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// skipStageStep is a helper function to plan and
+// report a step in the stage as skipped.
+func (c *client) skipStageStep(ctx context.Context, step *pipeline.Container) error {
+	c.logger.Infof("planning %s step", step.Name)
+	// plan the step
+	err := c.PlanStep(ctx, step)
+	if err != nil {
+		return fmt.Errorf("unable to plan step %s: %w", step.Name, err)
+	}
+
+	return c.skipStep(step)
+}
+
 // DestroyStage cleans up the stage after execution.
 func (c *client) DestroyStage(ctx context.Context, s *pipeline.Stage) error {
 	// update logger with extra metadata
@@ -170,15 +301,19 @@ func (c *client) DestroyStage(ctx context.Context, s *pipeline.Stage) error {
 		"stage": s.Name,
 	})
 
+	// create a slice to capture errors from destroying steps
+	var errs []error
+
 	// destroy the steps for the stage
 	for _, step := range s.Steps {
 		logger.Debugf("destroying %s step", step.Name)
 		// destroy the step
 		err := c.DestroyStep(ctx, step)
 		if err != nil {
-			return err
+			// continue destroying the remaining steps
+			errs = append(errs, fmt.Errorf("unable to destroy %s step: %w", step.Name, err))
 		}
 	}
 
-	return nil
+	return combineErrors(errs)
 }
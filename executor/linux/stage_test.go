@@ -6,14 +6,20 @@ package linux
 
 import (
 	"context"
+	"fmt"
 	"net/http/httptest"
+	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-vela/mock/server"
 	"github.com/go-vela/sdk-go/vela"
+	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
+	"github.com/go-vela/worker/runtime"
 	"github.com/go-vela/worker/runtime/docker"
 )
 
@@ -252,6 +258,332 @@ func TestExecutor_ExecStage_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_ExecStage_Parallel(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &concurrentRuntime{Engine: mock, delay: 100 * time.Millisecond}
+
+	stageMap := make(map[string]chan error)
+	stageMap["test"] = make(chan error)
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithStepConcurrency(2)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Stages: pipeline.StageSlice{
+			&pipeline.Stage{
+				Name: "test",
+				Steps: pipeline.ContainerSlice{
+					&pipeline.Container{
+						ID:     "__0_test_lint",
+						Image:  "alpine:latest",
+						Name:   "lint",
+						Number: 1,
+					},
+					&pipeline.Container{
+						ID:     "__0_test_unit",
+						Image:  "alpine:latest",
+						Name:   "unit",
+						Number: 2,
+					},
+					&pipeline.Container{
+						ID:     "__0_test_report",
+						Image:  "alpine:latest",
+						Name:   "report",
+						Number: 3,
+						Needs:  []string{"lint"},
+					},
+				},
+			},
+		},
+	})
+
+	// run test
+	err := e.ExecStage(context.Background(), e.pipeline.Stages[0], stageMap)
+	if err != nil {
+		t.Errorf("ExecStage returned err: %v", err)
+	}
+
+	if r.max != 2 {
+		t.Errorf("ExecStage ran %d steps at once, want 2", r.max)
+	}
+
+	report := r.index("start report")
+	if report < 0 || r.index("finish lint") > report {
+		t.Errorf("ExecStage started report before lint finished: %v", r.events)
+	}
+}
+
+func TestExecutor_ExecStage_MaxParallelSteps(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &concurrentRuntime{Engine: mock, delay: 50 * time.Millisecond}
+
+	stageMap := make(map[string]chan error)
+	stageMap["test"] = make(chan error)
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	stage := &pipeline.Stage{Name: "test"}
+
+	for i := 1; i <= 5; i++ {
+		stage.Steps = append(stage.Steps, &pipeline.Container{
+			ID:     fmt.Sprintf("__0_test_%d", i),
+			Image:  "alpine:latest",
+			Name:   fmt.Sprintf("step-%d", i),
+			Number: i,
+		})
+	}
+
+	e, _ := New(c, r)
+	e.WithStepConcurrency(5)
+	e.WithMaxParallelSteps(2)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Stages:  pipeline.StageSlice{stage},
+	})
+
+	// run test
+	err := e.ExecStage(context.Background(), stage, stageMap)
+	if err != nil {
+		t.Errorf("ExecStage returned err: %v", err)
+	}
+
+	if r.max != 2 {
+		t.Errorf("ExecStage ran %d steps at once, want 2", r.max)
+	}
+
+	if len(r.events) != 10 {
+		t.Errorf("ExecStage recorded %d events, want 10", len(r.events))
+	}
+}
+
+func TestExecutor_ExecStage_ParallelFailure(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &concurrentRuntime{Engine: mock, delay: 50 * time.Millisecond, exitCode: 1}
+
+	stageMap := make(map[string]chan error)
+	stageMap["test"] = make(chan error)
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	stage := &pipeline.Stage{Name: "test"}
+
+	for i := 1; i <= 4; i++ {
+		stage.Steps = append(stage.Steps, &pipeline.Container{
+			ID:     fmt.Sprintf("__0_test_%d", i),
+			Image:  "alpine:latest",
+			Name:   fmt.Sprintf("step-%d", i),
+			Number: i,
+		})
+	}
+
+	e, _ := New(c, r)
+	e.WithStepConcurrency(4)
+	e.WithBuild(&library.Build{Number: vela.Int(1), Status: vela.String(constants.StatusSuccess)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Stages:  pipeline.StageSlice{stage},
+	})
+
+	// run test
+	err := e.ExecStage(context.Background(), stage, stageMap)
+	if err != nil {
+		t.Errorf("ExecStage returned err: %v", err)
+	}
+
+	if r.max != 4 {
+		t.Errorf("ExecStage ran %d steps at once, want 4", r.max)
+	}
+
+	if e.build.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecStage build status is %s, want %s", e.build.GetStatus(), constants.StatusFailure)
+	}
+}
+
+func TestExecutor_ExecStage_ParallelSkipped(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &concurrentRuntime{Engine: mock, delay: 50 * time.Millisecond, exitCode: 1, fail: "lint"}
+
+	stageMap := make(map[string]chan error)
+	stageMap["test"] = make(chan error)
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	stage := &pipeline.Stage{
+		Name: "test",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:     "__0_test_lint",
+				Image:  "alpine:latest",
+				Name:   "lint",
+				Number: 1,
+			},
+			&pipeline.Container{
+				ID:     "__0_test_report",
+				Image:  "alpine:latest",
+				Name:   "report",
+				Number: 2,
+				Needs:  []string{"lint"},
+			},
+			&pipeline.Container{
+				ID:     "__0_test_notify",
+				Image:  "alpine:latest",
+				Name:   "notify",
+				Number: 3,
+				Needs:  []string{"lint"},
+				Ruleset: pipeline.Ruleset{
+					If: pipeline.Rules{Status: []string{constants.StatusFailure}},
+				},
+			},
+		},
+	}
+
+	e, _ := New(c, r)
+	e.WithStepConcurrency(2)
+	e.WithBuild(&library.Build{Number: vela.Int(1), Status: vela.String(constants.StatusSuccess)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Stages:  pipeline.StageSlice{stage},
+	})
+
+	// run test
+	err := e.ExecStage(context.Background(), stage, stageMap)
+	if err != nil {
+		t.Errorf("ExecStage returned err: %v", err)
+	}
+
+	if e.build.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecStage build status is %s, want %s", e.build.GetStatus(), constants.StatusFailure)
+	}
+
+	if r.index("start report") >= 0 {
+		t.Errorf("ExecStage should not have started report after lint failed: %v", r.events)
+	}
+
+	if r.index("start notify") < 0 {
+		t.Errorf("ExecStage should have started notify after lint failed: %v", r.events)
+	}
+
+	// the step skipped after the failure is reported
+	result, ok := e.steps.Load("__0_test_report")
+	if !ok {
+		t.Fatalf("ExecStage should have planned report")
+	}
+
+	if got := result.(*library.Step).GetStatus(); got != statusSkipped {
+		t.Errorf("ExecStage report status is %s, want %s", got, statusSkipped)
+	}
+}
+
+func TestExecutor_ExecStage_ParallelError(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &concurrentRuntime{Engine: mock, delay: 50 * time.Millisecond, broken: "lint"}
+
+	stageMap := make(map[string]chan error)
+	stageMap["test"] = make(chan error)
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	stage := &pipeline.Stage{
+		Name: "test",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:     "__0_test_lint",
+				Image:  "alpine:latest",
+				Name:   "lint",
+				Number: 1,
+			},
+			&pipeline.Container{
+				ID:     "__0_test_report",
+				Image:  "alpine:latest",
+				Name:   "report",
+				Number: 2,
+				Needs:  []string{"lint"},
+			},
+		},
+	}
+
+	e, _ := New(c, r)
+	e.WithStepConcurrency(2)
+	e.WithBuild(&library.Build{Number: vela.Int(1), Status: vela.String(constants.StatusSuccess)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Stages:  pipeline.StageSlice{stage},
+	})
+
+	// run test
+	err := e.ExecStage(context.Background(), stage, stageMap)
+	if err == nil {
+		t.Errorf("ExecStage should have returned err")
+	}
+
+	if r.index("start report") >= 0 {
+		t.Errorf("ExecStage should not have started report after lint errored: %v", r.events)
+	}
+
+	// the step skipped after the error is reported
+	result, ok := e.steps.Load("__0_test_report")
+	if !ok {
+		t.Fatalf("ExecStage should have planned report")
+	}
+
+	if got := result.(*library.Step).GetStatus(); got != statusSkipped {
+		t.Errorf("ExecStage report status is %s, want %s", got, statusSkipped)
+	}
+}
+
 func TestExecutor_DestroyStage_Success(t *testing.T) {
 	// setup
 	r, _ := docker.NewMock()
@@ -337,3 +669,148 @@ func TestExecutor_DestroyStage_Success(t *testing.T) {
 		t.Errorf("DestroyStage is %v, want nil", got)
 	}
 }
+
+func TestExecutor_DestroyStage_Failure(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &failingRuntime{Engine: mock, fail: "__0_clone_two"}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+
+	stage := &pipeline.Stage{
+		Name: "clone",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:    "__0_clone_one",
+				Image: "alpine:latest",
+				Name:  "one",
+			},
+			&pipeline.Container{
+				ID:    "__0_clone_two",
+				Image: "alpine:latest",
+				Name:  "two",
+			},
+			&pipeline.Container{
+				ID:    "__0_clone_three",
+				Image: "alpine:latest",
+				Name:  "three",
+			},
+		},
+	}
+
+	want := []string{"__0_clone_one", "__0_clone_two", "__0_clone_three"}
+
+	// run test
+	got := e.DestroyStage(context.Background(), stage)
+
+	if got == nil {
+		t.Errorf("DestroyStage should have returned err")
+	}
+
+	if !reflect.DeepEqual(r.removed, want) {
+		t.Errorf("DestroyStage removed %v, want %v", r.removed, want)
+	}
+}
+
+// failingRuntime is a runtime that fails
+// removing the container with the provided ID.
+type failingRuntime struct {
+	runtime.Engine
+
+	fail    string
+	removed []string
+}
+
+// RemoveContainer records the container removal and
+// fails if the container matches the provided ID.
+func (r *failingRuntime) RemoveContainer(ctx context.Context, ctn *pipeline.Container) error {
+	r.removed = append(r.removed, ctn.ID)
+
+	if ctn.ID == r.fail {
+		return fmt.Errorf("unable to remove container %s", ctn.ID)
+	}
+
+	return nil
+}
+
+// concurrentRuntime is a runtime that tracks the
+// containers being waited on at the same time.
+type concurrentRuntime struct {
+	runtime.Engine
+
+	delay    time.Duration
+	exitCode int
+	fail     string
+	broken   string
+
+	mu      sync.Mutex
+	running int
+	max     int
+	events  []string
+}
+
+// WaitContainer records the container running for the delay.
+func (r *concurrentRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	r.mu.Lock()
+	r.running++
+
+	if r.running > r.max {
+		r.max = r.running
+	}
+
+	r.events = append(r.events, "start "+ctn.Name)
+	r.mu.Unlock()
+
+	time.Sleep(r.delay)
+
+	// check if the container is unable to be waited on
+	if ctn.Name == r.broken {
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+
+		return fmt.Errorf("unable to wait for container %s", ctn.Name)
+	}
+
+	r.mu.Lock()
+	r.running--
+	r.events = append(r.events, "finish "+ctn.Name)
+	r.mu.Unlock()
+
+	return nil
+}
+
+// InspectContainer sets the exit code for the container.
+func (r *concurrentRuntime) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
+	err := r.Engine.InspectContainer(ctx, ctn)
+	if err != nil {
+		return err
+	}
+
+	// check if only the provided container fails
+	if len(r.fail) == 0 || ctn.Name == r.fail {
+		ctn.ExitCode = r.exitCode
+	}
+
+	return nil
+}
+
+// index returns the position of the recorded event.
+func (r *concurrentRuntime) index(event string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, e := range r.events {
+		if e == event {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -6,25 +6,36 @@ package linux
 
 import (
 	"bufio"
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/go-vela/worker/runtime"
 	"github.com/go-vela/worker/version"
 
+	"github.com/go-vela/sdk-go/vela"
+
 	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 
 	"github.com/drone/envsubst"
+	"github.com/drone/envsubst/parse"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
 )
 
 // CreateStep prepares the step for execution.
-func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) error {
+//
+// When the step fails to be created, the error is uploaded
+// to the step log so the reason is visible to the user.
+func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) (err error) {
 	// update engine logger with extra metadata
 	logger := c.logger.WithFields(logrus.Fields{
 		"step": ctn.Name,
@@ -33,20 +44,49 @@ func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) error
 	ctn.Environment["BUILD_HOST"] = c.Hostname
 	ctn.Environment["VELA_HOST"] = c.Hostname
 	ctn.Environment["VELA_VERSION"] = version.Version.String()
-	// TODO: remove hardcoded reference
-	ctn.Environment["VELA_RUNTIME"] = "docker"
+	ctn.Environment["VELA_RUNTIME"] = c.Runtime.Name()
 	ctn.Environment["VELA_DISTRIBUTION"] = "linux"
 
-	// TODO: remove hardcoded reference
-	if ctn.Name == "init" {
+	// inject the global environment into the step
+	for k, v := range c.GlobalEnvironment {
+		// skip variables already set for the step
+		if _, ok := ctn.Environment[k]; ok {
+			continue
+		}
+
+		ctn.Environment[k] = v
+	}
+
+	// check if the container is the init step
+	if c.isInitStep(ctn) {
 		return nil
 	}
 
-	logger.Debug("setting up container")
-	// setup the runtime container
-	err := c.Runtime.SetupContainer(ctx, ctn)
-	if err != nil {
-		return err
+	defer func() {
+		// check if the step failed to be created
+		if err != nil {
+			c.logStepError(ctn, err)
+		}
+	}()
+
+	// check if the build was canceled before setting up the container
+	if ctx.Err() != nil {
+		return fmt.Errorf("unable to create %s step: %w", ctn.Name, ctx.Err())
+	}
+
+	// check if the step should be run in dry run mode
+	if !c.DryRun {
+		logger.Debug("setting up container")
+		// setup the runtime container
+		err := c.Runtime.SetupContainer(ctx, ctn)
+		if err != nil {
+			return err
+		}
+	}
+
+	// check if the build was canceled while setting up the container
+	if ctx.Err() != nil {
+		return fmt.Errorf("unable to create %s step: %w", ctn.Name, ctx.Err())
 	}
 
 	logger.Debug("injecting secrets")
@@ -56,6 +96,37 @@ func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) error
 		return err
 	}
 
+	logger.Debug("resolving secrets")
+	// resolve secrets for step
+	err = c.resolveSecrets(ctx, ctn)
+	if err != nil {
+		return err
+	}
+
+	// check if the build was canceled while injecting secrets
+	if ctx.Err() != nil {
+		return fmt.Errorf("unable to create %s step: %w", ctn.Name, ctx.Err())
+	}
+
+	// substitute the environment variables for the step
+	err = c.substituteStep(ctn, logger)
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("validating required environment")
+	// validate the required environment for the step
+	err = validateEnv(ctn)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// substituteStep is a helper function to substitute the
+// environment variables referenced in the step configuration.
+func (c *client) substituteStep(ctn *pipeline.Container, logger *logrus.Entry) error {
 	logger.Debug("marshaling configuration")
 	// marshal container configuration
 	body, err := json.Marshal(ctn)
@@ -63,21 +134,40 @@ func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) error
 		return fmt.Errorf("unable to marshal configuration: %v", err)
 	}
 
+	// capture which variable references are plain ${VAR} references
+	plain := plainRefs(escapeBody(string(body)))
+	ref := 0
+
 	// create substitute function
 	subFunc := func(name string) string {
-		env := ctn.Environment[name]
-		if strings.Contains(env, "\n") {
-			env = fmt.Sprintf("%q", env)
+		env, ok := ctn.Environment[name]
+
+		// check if the reference is a plain ${VAR} reference
+		isPlain := ref < len(plain) && plain[ref]
+		ref++
+
+		// leave unresolved plain variables intact for lenient
+		// substitution so references with a default still apply it
+		if !ok && isPlain && c.LenientSubstitution {
+			return fmt.Sprintf("${%s}", name)
 		}
 
-		return env
+		return escapeEnv(env)
 	}
 
 	logger.Debug("substituting environment")
 	// substitute the environment variables
-	subStep, err := envsubst.Eval(string(body), subFunc)
+	subStep, err := envsubst.Eval(escapeBody(string(body)), subFunc)
 	if err != nil {
-		return fmt.Errorf("unable to substitute environment variables: %v", err)
+		// check if substitution failures should be ignored
+		if !c.LenientSubstitution {
+			return fmt.Errorf("unable to substitute environment variables: %v", err)
+		}
+
+		logger.Warnf("unable to substitute environment variables: %v", err)
+
+		// keep the configuration without substitution
+		subStep = string(body)
 	}
 
 	logger.Debug("unmarshaling configuration")
@@ -90,9 +180,36 @@ func (c *client) CreateStep(ctx context.Context, ctn *pipeline.Container) error
 	return nil
 }
 
+// validateEnv is a helper function to verify the environment
+// variables required by the step are set after substitution.
+func validateEnv(ctn *pipeline.Container) error {
+	var missing []string
+
+	// check each of the environment variables required by the step
+	for _, name := range strings.Split(ctn.Environment[requiredEnv], ",") {
+		name = strings.TrimSpace(name)
+		if len(name) == 0 {
+			continue
+		}
+
+		if len(ctn.Environment[name]) == 0 {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s step is missing required environment variables: %s", ctn.Name, strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
 // PlanStep defines a function that prepares the step for execution.
 func (c *client) PlanStep(ctx context.Context, ctn *pipeline.Container) error {
-	var err error
+	var (
+		step *library.Step
+		log  *library.Log
+	)
 
 	b := c.build
 	r := c.repo
@@ -106,48 +223,97 @@ func (c *client) PlanStep(ctx context.Context, ctn *pipeline.Container) error {
 	s := new(library.Step)
 	s.SetName(ctn.Name)
 	s.SetNumber(ctn.Number)
-	s.SetStatus(constants.StatusRunning)
-	s.SetStarted(time.Now().UTC().Unix())
+	s.SetStatus(constants.StatusPending)
 	s.SetHost(ctn.Environment["VELA_HOST"])
 	s.SetRuntime(ctn.Environment["VELA_RUNTIME"])
 	s.SetDistribution(ctn.Environment["VELA_DISTRIBUTION"])
 
-	logger.Debug("uploading step state")
-	// send API call to update the step
-	s, _, err = c.Vela.Step.Update(r.GetOrg(), r.GetName(), b.GetNumber(), s)
-	if err != nil {
-		return err
+	// check if the container is the init step
+	//
+	// the init step is running as soon as it is planned
+	// since it captures the output for creating the build
+	if c.isInitStep(ctn) {
+		s.SetStatus(constants.StatusRunning)
+		s.SetStarted(time.Now().UTC().Unix())
 	}
 
-	s.SetStatus(constants.StatusSuccess)
+	// create an error group to send the API calls concurrently
+	calls := new(errgroup.Group)
 
-	// add a step to a map
-	c.steps.Store(ctn.ID, s)
+	calls.Go(func() error {
+		logger.Debug("uploading step state")
+		// send the step update to the Vela server
+		err := c.updateStep(s)
+		if err != nil {
+			return fmt.Errorf("unable to upload step state: %w", err)
+		}
 
-	// get the step log here
-	logger.Debug("retrieve step log")
-	// send API call to capture the step log
-	l, _, err := c.Vela.Log.GetStep(r.GetOrg(), r.GetName(), b.GetNumber(), s.GetNumber())
+		step = s
+
+		return nil
+	})
+
+	calls.Go(func() error {
+		logger.Debug("retrieve step log")
+		// send API call to capture the step log
+		result, _, err := c.Vela.Log.GetStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number)
+		if err != nil {
+			return fmt.Errorf("unable to retrieve step log: %w", err)
+		}
+
+		log = result
+
+		return nil
+	})
+
+	// wait for the API calls to complete
+	err := calls.Wait()
 	if err != nil {
 		return err
 	}
 
+	step.SetStatus(constants.StatusSuccess)
+
+	// add a step to a map
+	c.steps.Store(ctn.ID, step)
+
 	// add a step log to a map
-	c.stepLogs.Store(ctn.ID, l)
+	c.stepLogs.Store(ctn.ID, log)
 
 	return nil
 }
 
 // ExecStep runs a step.
+//
+// The step hooks are run before and after the step is executed.
 func (c *client) ExecStep(ctx context.Context, ctn *pipeline.Container) error {
-	// TODO: remove hardcoded reference
-	if ctn.Name == "init" {
+	// check if the container is the init step
+	if c.isInitStep(ctn) {
 		return nil
 	}
 
-	b := c.build
-	r := c.repo
+	// run the hooks before executing the step
+	for _, hook := range c.StepHooks {
+		err := hook.BeforeStep(ctx, ctn)
+		if err != nil {
+			return fmt.Errorf("unable to run hook before %s step: %w", ctn.Name, err)
+		}
+	}
+
+	// execute the step
+	err := c.execStep(ctx, ctn)
+
+	// run the hooks after executing the step
+	for _, hook := range c.StepHooks {
+		hook.AfterStep(ctx, ctn, err)
+	}
+
+	return err
+}
 
+// execStep is a helper function to run the step container
+// and report the result of the step.
+func (c *client) execStep(ctx context.Context, ctn *pipeline.Container) error {
 	result, ok := c.stepLogs.Load(ctn.ID)
 	if !ok {
 		return fmt.Errorf("unable to get step log from client")
@@ -160,80 +326,866 @@ func (c *client) ExecStep(ctx context.Context, ctn *pipeline.Container) error {
 		"step": ctn.Name,
 	})
 
-	logger.Debug("running container")
-	// run the runtime container
-	err := c.Runtime.RunContainer(ctx, c.pipeline, ctn)
+	// check if the step matches its ruleset
+	if !c.matchStep(ctn) {
+		logger.Info("skipping step since its ruleset did not match")
+
+		return c.skipStep(ctn)
+	}
+
+	logger.Debug("waiting for step slot")
+	// wait for a slot to run the step container
+	release, err := c.acquireStep(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer release()
+
+	logger.Debug("starting step")
+	// report the step as running
+	err = c.startStep(ctn)
+	if err != nil {
+		return err
+	}
+
+	// check if the step should be run in dry run mode
+	if c.DryRun {
+		c.dryRunStep(ctn)
+
+		return nil
+	}
+
+	// run the step container until it exits successfully
+	// or the configured retries are exhausted
+	logs, stepErr, err := c.runStep(ctx, ctn, l, logger)
+	if err != nil {
+		return err
+	}
+
+	// do not wait for detached containers
+	if ctn.Detach {
+		return nil
+	}
+
+	logger.Debug("reporting exit code")
+	// report the container exit code for the step
+	err = c.reportStep(ctn, stepErr)
 	if err != nil {
 		return err
 	}
 
-	// create new buffer for uploading logs
-	logs := new(bytes.Buffer)
-	go func() error {
+	logger.Debug("waiting for logs")
+	// wait for the container logs to finish uploading
+	<-logs.done
+
+	err = logs.err
+	if err != nil {
+		return fmt.Errorf("unable to stream logs for %s step: %w", ctn.Name, err)
+	}
+
+	return nil
+}
+
+// runStep is a helper function to run the step container
+// until it exits successfully or the configured retries are
+// exhausted, returning the stream of logs from the last
+// attempt and the error the step is reported with.
+func (c *client) runStep(
+	ctx context.Context,
+	ctn *pipeline.Container,
+	l *library.Log,
+	logger *logrus.Entry,
+) (*stream, error, error) {
+	var marker string
+
+	for attempt := 0; ; attempt++ {
+		// capture the time the step started running
+		start := time.Now()
+
+		logger.Debug("running container")
+		// run the runtime container
+		err := c.Runtime.RunContainer(ctx, c.pipeline, ctn)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// stream the logs from the container for the attempt
+		logs := c.tailStep(ctx, ctn, l, marker, logger)
+
+		// do not wait for detached containers
+		if ctn.Detach {
+			return logs, nil, nil
+		}
+
+		// wait for the container to exit
+		stepErr, err := c.waitStep(ctx, ctn, start, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// check if the step should be retried
+		if ctn.ExitCode == 0 || stepErr != nil || attempt >= c.StepRetries || ctx.Err() != nil {
+			return logs, stepErr, nil
+		}
+
+		logger.Infof("retrying %s step after exit code %d", ctn.Name, ctn.ExitCode)
+
+		marker = fmt.Sprintf("[retrying: attempt %d of %d after exit code %d]\n", attempt+2, c.StepRetries+1, ctn.ExitCode)
+
+		// clean up the failed attempt before running the step again
+		l, err = c.resetStep(ctx, ctn, l, logs, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+// tailStep is a helper function to stream the output of the
+// step container to the step log, prefixed with the marker
+// for the attempt.
+//
+// The logs streaming from the container are tracked so they are
+// captured when the step is destroyed, even if the step returns
+// before the logs have finished uploading.
+func (c *client) tailStep(
+	ctx context.Context,
+	ctn *pipeline.Container,
+	l *library.Log,
+	marker string,
+	logger *logrus.Entry,
+) *stream {
+	logs := newStream()
+	c.streams.Store(ctn.ID, logs)
+
+	go func() {
 		logger.Debug("tailing container")
 		// tail the runtime container
-		rc, err := c.Runtime.TailContainer(ctx, ctn)
+		rc, err := c.tailContainer(ctx, ctn)
 		if err != nil {
-			return err
+			logs.finish(err)
+			return
 		}
 		defer rc.Close()
 
-		// create new scanner from the container output
-		scanner := bufio.NewScanner(rc)
+		output := io.Reader(rc)
+
+		// prefix the output with the marker for the attempt
+		if len(marker) > 0 {
+			output = io.MultiReader(strings.NewReader(marker), rc)
+		}
+
+		// stream the container output to the step log
+		logs.finish(c.streamStep(ctx, ctn, output, l))
+	}()
+
+	return logs
+}
 
-		// scan entire container output
-		for scanner.Scan() {
-			// write all the logs from the scanner
-			logs.Write(append(scanner.Bytes(), []byte("\n")...))
+// waitStep is a helper function to wait for the step container
+// to exit and inspect it, returning the error the step is
+// reported with if the container was killed or exceeded the
+// configured step timeout.
+func (c *client) waitStep(
+	ctx context.Context,
+	ctn *pipeline.Container,
+	start time.Time,
+	logger *logrus.Entry,
+) (error, error) {
+	var (
+		waitCtx context.Context
+		cancel  context.CancelFunc
+	)
 
-			// if we have at least 1000 bytes in our buffer
-			if logs.Len() > 1000 {
-				logger.Trace(logs.String())
+	// check if a timeout is configured for the step
+	if c.StepTimeout > 0 {
+		// create a context bounded by the step timeout
+		waitCtx, cancel = context.WithTimeout(ctx, c.StepTimeout)
+	} else {
+		// create a context for waiting on the container
+		waitCtx, cancel = context.WithCancel(ctx)
+	}
 
-				// update the existing log with the new bytes
-				l.SetData(append(l.GetData(), logs.Bytes()...))
+	defer cancel()
 
-				logger.Debug("appending logs")
-				// send API call to update the logs for the step
-				l, _, err = c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
-				if err != nil {
-					return err
-				}
+	logger.Debug("waiting for container")
+	// wait for the runtime container
+	err := c.Runtime.WaitContainer(waitCtx, ctn)
+	if err != nil {
+		// record the failed step in the metrics
+		observeStep(ctn, start, err)
 
-				// flush the buffer of logs
-				logs.Reset()
+		// check if the step exceeded the configured timeout
+		if errors.Is(waitCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			logger.Debug("removing timed out container")
+			// remove the runtime container
+			rmErr := c.Runtime.RemoveContainer(ctx, ctn)
+			if rmErr != nil {
+				logger.Errorf("unable to remove timed out container: %v", rmErr)
 			}
+
+			logger.Errorf("%s step exceeded timeout of %v", ctn.Name, c.StepTimeout)
+
+			// report the step as killed by the timeout
+			return fmt.Errorf("%w after exceeding timeout of %v", runtime.ErrKilled, c.StepTimeout), nil
+		}
+
+		return nil, err
+	}
+
+	logger.Debug("inspecting container")
+	// inspect the runtime container
+	err = c.Runtime.InspectContainer(ctx, ctn)
+
+	// record the completed step in the metrics
+	observeStep(ctn, start, err)
+
+	// capture if the container was killed in the runtime
+	if errors.Is(err, runtime.ErrOOMKilled) || errors.Is(err, runtime.ErrKilled) {
+		logger.Errorf("%s step %v", ctn.Name, err)
+
+		return err, nil
+	}
+
+	return nil, err
+}
+
+// resetStep is a helper function to wait for the logs from the
+// failed attempt of the step to finish uploading and remove the
+// container so the step is run again, returning the step log
+// updated by the failed attempt.
+func (c *client) resetStep(
+	ctx context.Context,
+	ctn *pipeline.Container,
+	l *library.Log,
+	logs *stream,
+	logger *logrus.Entry,
+) (*library.Log, error) {
+	// wait for the logs from the failed attempt to finish uploading
+	<-logs.done
+
+	err := logs.err
+	if err != nil {
+		return nil, fmt.Errorf("unable to stream logs for %s step: %w", ctn.Name, err)
+	}
+
+	// capture the step log updated by the failed attempt
+	result, ok := c.stepLogs.Load(ctn.ID)
+	if ok {
+		l = result.(*library.Log)
+	}
+
+	logger.Debug("removing failed container")
+	// remove the runtime container before running it again
+	err = c.Runtime.RemoveContainer(ctx, ctn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctn.ExitCode = 0
+
+	return l, nil
+}
+
+// tailContainer is a helper function to tail the container,
+// retrying with backoff while the container that was started
+// is not yet ready to have its logs captured.
+func (c *client) tailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
+	backoff := tailRetryBackoff
+
+	for i := 0; ; i++ {
+		// tail the runtime container
+		rc, err := c.Runtime.TailContainer(ctx, ctn)
+		if err == nil {
+			return rc, nil
+		}
+
+		// check if all retries have been attempted
+		if i >= tailRetries || ctx.Err() != nil {
+			return nil, err
+		}
+
+		c.logger.Debugf("unable to tail %s container: %v. Retrying in %v", ctn.Name, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
 		}
-		logger.Trace(logs.String())
 
-		// update the existing log with the last bytes
-		l.SetData(append(l.GetData(), logs.Bytes()...))
+		// double the backoff for the next retry
+		backoff *= 2
+	}
+}
 
-		logger.Debug("uploading logs")
+// streamStep is a helper function to capture the container
+// output from the reader and upload it to the step log.
+func (c *client) streamStep(ctx context.Context, ctn *pipeline.Container, rc io.Reader, l *library.Log) error {
+	b := c.build
+	r := c.repo
+
+	return c.streamLogs(ctx, "step", ctn, rc, l, func(l *library.Log) (*library.Log, *vela.Response, error) {
 		// send API call to update the logs for the step
-		l, _, err = c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
+		log, resp, err := c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
 		if err != nil {
-			return err
+			return nil, resp, err
 		}
 
-		return nil
-	}()
+		// track the updated step log for the step
+		c.stepLogs.Store(ctn.ID, log)
 
-	// do not wait for detached containers
-	if ctn.Detach {
+		return log, resp, nil
+	})
+}
+
+// streamLogs is a helper function to capture the container
+// output from the reader and upload it with the update
+// function to the log for the step or service.
+//
+// The output is masked, flushed on the configured interval,
+// retried on failure and sent to the log sinks and the
+// subscribers of the container.
+func (c *client) streamLogs(
+	ctx context.Context,
+	kind string,
+	ctn *pipeline.Container,
+	rc io.Reader,
+	l *library.Log,
+	update func(*library.Log) (*library.Log, *vela.Response, error),
+) error {
+	// update engine logger with extra metadata
+	logger := c.logger.WithFields(logrus.Fields{
+		kind: ctn.Name,
+	})
+
+	// create new capture for the logs of the container
+	capture := &logCapture{
+		client: c,
+		kind:   kind,
+		ctn:    ctn,
+		logger: logger,
+		update: update,
+		log:    l,
+	}
+
+	var wg sync.WaitGroup
+
+	done := make(chan struct{})
+
+	// check if the logs should be flushed on an interval
+	if c.LogFlushInterval > 0 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			capture.flush(ctx, c.LogFlushInterval, done)
+		}()
+	}
+
+	// capture the container output until it is read completely
+	readErr, err := c.scanLogs(ctx, capture, rc)
+
+	// stop flushing the logs on an interval
+	close(done)
+	wg.Wait()
+
+	if err != nil {
+		return err
+	}
+
+	logger.Debug("uploading logs")
+	// upload the last bytes to the log
+	err = capture.upload(ctx, true)
+	if err != nil {
+		return err
+	}
+
+	// check if the container output was not read completely
+	if readErr != nil {
+		return fmt.Errorf("unable to read container output: %w", readErr)
+	}
+
+	return nil
+}
+
+// scanLogs is a helper function to mask the container output
+// from the reader and write it to the captured logs.
+//
+// The error reading the container output is returned
+// separately from the error capturing the logs so the
+// logs read before the error are still uploaded.
+func (c *client) scanLogs(ctx context.Context, capture *logCapture, rc io.Reader) (error, error) {
+	// create new masker from the secrets injected into the container
+	mask := newMasker(c.stepSecrets(capture.ctn))
+
+	// create new scanner from the container output
+	scanner := c.newLogScanner(rc)
+
+	// track the raw output held back from the last chunk
+	var pending []byte
+
+	// scan entire container output
+	for scanner.Scan() {
+		// drain the output once the logs are truncated
+		// so the container is not blocked writing logs
+		if capture.truncated {
+			continue
+		}
+
+		line := scanner.Bytes()
+
+		// check if the output is read as raw bytes
+		if c.LogRaw {
+			// mask the chunk without modifying the bytes, holding
+			// back the end of the chunk that may be the start of
+			// a secret split across the next chunk
+			line, pending = mask.MaskChunk(append(pending, line...))
+
+			// skip the chunk if it was entirely held back
+			if len(line) == 0 {
+				continue
+			}
+		} else {
+			line = c.formatLine(mask, line)
+		}
+
+		err := capture.write(ctx, line)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// capture the error reading the container output
+	readErr := scanner.Err()
+	if readErr != nil {
+		capture.logger.Errorf("unable to read container output: %v", readErr)
+	}
+
+	// check if output was held back from the last chunk
+	if !capture.truncated && len(pending) > 0 {
+		err := capture.write(ctx, mask.Mask(pending))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return readErr, nil
+}
+
+// newLogScanner is a helper function to create the scanner
+// reading the container output in raw chunks or in lines.
+func (c *client) newLogScanner(rc io.Reader) *bufio.Scanner {
+	// create new scanner from the container output
+	scanner := bufio.NewScanner(rc)
+
+	// check if the output should be read as raw bytes
+	if c.LogRaw {
+		// read the output in chunks up to the chunk size
+		scanner.Buffer(make([]byte, logChunkSize), logChunkSize)
+		scanner.Split(scanChunks)
+
+		return scanner
+	}
+
+	// allow lines up to the max size and split longer lines
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), c.LogLineMaxSize)
+	scanner.Split(scanLines(c.LogLineMaxSize))
+
+	return scanner
+}
+
+// formatLine is a helper function to mask the line of container
+// output and format it with the configured log options.
+func (c *client) formatLine(mask *masker, line []byte) []byte {
+	// check if the ANSI escape sequences should be removed
+	if c.LogStripANSI {
+		line = stripANSI(line)
+	}
+
+	line = append(mask.Mask(line), []byte("\n")...)
+
+	// check if the line should be prefixed with a timestamp
+	if c.LogTimestamps {
+		line = append([]byte(time.Now().UTC().Format(time.RFC3339)+" "), line...)
+	}
+
+	return line
+}
+
+// scanLines is a helper function to create a split function
+// for the scanner that splits the output into lines, splitting
+// a line longer than the max size into multiple lines instead
+// of failing with bufio.ErrTooLong.
+func scanLines(max int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+
+		// check if the line exceeds the max size
+		if advance == 0 && err == nil && len(data) >= max {
+			return max, data[:max], nil
+		}
+
+		return advance, token, err
+	}
+}
+
+// scanChunks is a split function for the scanner that
+// returns the raw output read so far as a single chunk.
+func scanChunks(data []byte, atEOF bool) (int, []byte, error) {
+	// check if there is no output to return
+	if len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	return len(data), data, nil
+}
+
+// escapeBody is a helper function to preserve the escaped
+// backslashes in the JSON configuration for a container
+// when the substitution treats them as escape sequences.
+func escapeBody(body string) string {
+	// check if the substitution unescapes backslashes
+	out, err := envsubst.Eval(`\\`, func(string) string { return "" })
+	if err != nil || out != `\` {
+		return body
+	}
+
+	return strings.ReplaceAll(body, `\`, `\\`)
+}
+
+// plainRefs is a helper function to capture, in the order
+// the substitution resolves them, whether each variable
+// reference in the body is a plain ${VAR} reference.
+func plainRefs(body string) []bool {
+	tree, err := parse.Parse(body)
+	if err != nil {
 		return nil
 	}
 
-	logger.Debug("waiting for container")
-	// wait for the runtime container
-	err = c.Runtime.WaitContainer(ctx, ctn)
+	var walk func(n parse.Node, refs []bool) []bool
+
+	walk = func(n parse.Node, refs []bool) []bool {
+		switch node := n.(type) {
+		case *parse.ListNode:
+			for _, n := range node.Nodes {
+				refs = walk(n, refs)
+			}
+		case *parse.FuncNode:
+			// arguments are resolved before the reference itself
+			for _, n := range node.Args {
+				refs = walk(n, refs)
+			}
+
+			refs = append(refs, len(node.Name) == 0)
+		}
+
+		return refs
+	}
+
+	return walk(tree.Root, nil)
+}
+
+// escapeEnv is a helper function to escape the environment
+// value so it can be safely substituted into the JSON
+// configuration for a container.
+func escapeEnv(value string) string {
+	// JSON encode the value to escape quotes,
+	// backslashes, newlines and control characters
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	// trim the quotes surrounding the encoded string
+	return string(data[1 : len(data)-1])
+}
+
+// dryRunStep is a helper function to log the resolved
+// configuration for the step instead of running it.
+func (c *client) dryRunStep(ctn *pipeline.Container) {
+	// update engine logger with extra metadata
+	logger := c.logger.WithFields(logrus.Fields{
+		"step": ctn.Name,
+	})
+
+	// create new masker from the secrets injected into the container
+	mask := newMasker(c.stepSecrets(ctn))
+
+	// create a sorted list of the environment keys
+	keys := make([]string, 0, len(ctn.Environment))
+	for key := range ctn.Environment {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	logger.Infof("dry run: would run image %s", ctn.Image)
+
+	// log the entrypoint for the container
+	if len(ctn.Entrypoint) > 0 {
+		logger.Infof("dry run: entrypoint %s", mask.Mask([]byte(strings.Join(ctn.Entrypoint, " "))))
+	}
+
+	// log the commands for the container
+	for _, command := range ctn.Commands {
+		logger.Infof("dry run: command %s", mask.Mask([]byte(command)))
+	}
+
+	// log the environment for the container
+	for _, key := range keys {
+		logger.Debugf("dry run: environment %s=%s", key, mask.Mask([]byte(ctn.Environment[key])))
+	}
+}
+
+// logStepError is a helper function to upload the
+// error from creating the step to the step log.
+func (c *client) logStepError(ctn *pipeline.Container, stepErr error) {
+	b := c.build
+	r := c.repo
+
+	// update engine logger with extra metadata
+	logger := c.logger.WithFields(logrus.Fields{
+		"step": ctn.Name,
+	})
+
+	logger.Debug("retrieve step log")
+	// send API call to capture the step log
+	l, _, err := c.Vela.Log.GetStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number)
+	if err != nil {
+		logger.Errorf("unable to retrieve step log: %v", err)
+
+		return
+	}
+
+	// create new masker from the secrets injected into the container
+	mask := newMasker(c.stepSecrets(ctn))
+
+	// append the masked error to the step log
+	l.SetData(append(l.GetData(), mask.Mask([]byte(fmt.Sprintf("unable to create step: %v\n", stepErr)))...))
+
+	logger.Debug("uploading step error")
+	// send API call to update the logs for the step
+	_, _, err = c.Vela.Log.UpdateStep(r.GetOrg(), r.GetName(), b.GetNumber(), ctn.Number, l)
+	if err != nil {
+		logger.Errorf("unable to upload step error: %v", err)
+	}
+}
+
+// startStep is a helper function to report the
+// planned step as running before it is executed.
+func (c *client) startStep(ctn *pipeline.Container) error {
+	result, ok := c.steps.Load(ctn.ID)
+	if !ok {
+		return fmt.Errorf("unable to get step from client")
+	}
+
+	s := result.(*library.Step)
+
+	// update the step fields for the running step
+	s.SetStatus(constants.StatusRunning)
+	s.SetStarted(time.Now().UTC().Unix())
+
+	c.logger.Infof("uploading %s step running state", ctn.Name)
+	// send the step update to the Vela server
+	err := c.updateStep(s)
 	if err != nil {
 		return err
 	}
 
-	logger.Debug("inspecting container")
-	// inspect the runtime container
-	err = c.Runtime.InspectContainer(ctx, ctn)
+	// the step is assumed successful until the
+	// exit code for the container is reported
+	s.SetStatus(constants.StatusSuccess)
+
+	return nil
+}
+
+// matchStep is a helper function to evaluate the
+// ruleset for the step against the running build.
+//
+// A step without a ruleset only runs while the build
+// is successful.
+func (c *client) matchStep(ctn *pipeline.Container) bool {
+	b := c.build
+	r := c.repo
+
+	// the build is treated as successful until a step fails
+	status := constants.StatusSuccess
+	if strings.EqualFold(c.buildStatus(), constants.StatusFailure) {
+		status = constants.StatusFailure
+	}
+
+	// check if the step has an empty ruleset
+	if ctn.Ruleset.If.Empty() && ctn.Ruleset.Unless.Empty() {
+		return status == constants.StatusSuccess
+	}
+
+	// create the data the ruleset is matched against
+	data := &pipeline.RuleData{
+		Branch: b.GetBranch(),
+		Event:  b.GetEvent(),
+		Repo:   r.GetFullName(),
+		Status: status,
+	}
+
+	// capture the tag from the reference for tag events
+	if strings.EqualFold(b.GetEvent(), constants.EventTag) {
+		data.Tag = strings.TrimPrefix(b.GetRef(), "refs/tags/")
+	}
+
+	return ctn.Ruleset.Match(data)
+}
+
+// skipStep is a helper function to report the planned
+// step as skipped without running the container.
+func (c *client) skipStep(ctn *pipeline.Container) error {
+	result, ok := c.steps.Load(ctn.ID)
+	if !ok {
+		return fmt.Errorf("unable to get step from client")
+	}
+
+	s := result.(*library.Step)
+
+	// update the step fields for the skipped step
+	s.SetStatus(statusSkipped)
+	s.SetStarted(time.Now().UTC().Unix())
+	s.SetFinished(time.Now().UTC().Unix())
+
+	c.logger.Infof("uploading %s step skipped state", ctn.Name)
+	// send the step update to the Vela server
+	err := c.updateStep(s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// acquireStep is a helper function to wait for a slot to run
+// a step container when the parallel steps are limited. The
+// returned function releases the slot for the next step.
+func (c *client) acquireStep(ctx context.Context) (func(), error) {
+	// check if the parallel steps are limited
+	if c.stepSlots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case c.stepSlots <- struct{}{}:
+		return func() { <-c.stepSlots }, nil
+	}
+}
+
+// reportStep is a helper function to update the step status in
+// the API from the container exit code and the runtime error.
+func (c *client) reportStep(ctn *pipeline.Container, stepErr error) error {
+	result, ok := c.steps.Load(ctn.ID)
+	if !ok {
+		return fmt.Errorf("unable to get step from client")
+	}
+
+	s := result.(*library.Step)
+
+	// update the step fields from the exit code
+	s.SetExitCode(ctn.ExitCode)
+	s.SetStatus(constants.StatusSuccess)
+
+	// check the step exit code
+	if ctn.ExitCode != 0 {
+		s.SetStatus(constants.StatusFailure)
+	}
+
+	// check if the step failed in the runtime
+	if stepErr != nil {
+		s.SetError(stepErr.Error())
+		s.SetStatus(constants.StatusFailure)
+	}
+
+	// check if the step was killed in the runtime
+	if errors.Is(stepErr, runtime.ErrKilled) {
+		s.SetStatus(constants.StatusKilled)
+	}
+
+	c.logger.Infof("uploading %s step exit code", ctn.Name)
+	// send the step update to the Vela server
+	err := c.updateStep(s)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// stepFailed is a helper function to check if the step
+// exited non-zero or was reported as failed or killed.
+func stepFailed(ctn *pipeline.Container, s *library.Step) bool {
+	return ctn.ExitCode != 0 ||
+		s.GetStatus() == constants.StatusFailure ||
+		s.GetStatus() == constants.StatusKilled
+}
+
+// stepContext is a helper function to create the context for
+// executing a step. When the build context is done, the step
+// is allowed to complete within the shutdown timeout before
+// the step context is canceled. Killed or timed out builds
+// cancel the step context immediately.
+func (c *client) stepContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	// create a context that is not canceled with the build context
+	stepCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-stepCtx.Done():
+			return
+		case <-ctx.Done():
+		}
+
+		// check if the build was killed or timed out
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) ||
+			strings.EqualFold(c.buildStatus(), constants.StatusKilled) {
+			cancel()
+
+			return
+		}
+
+		c.logger.Infof("waiting %v for running step to complete", c.ShutdownTimeout)
+
+		// create a timer for the shutdown timeout
+		timer := time.NewTimer(c.ShutdownTimeout)
+		defer timer.Stop()
+
+		select {
+		case <-stepCtx.Done():
+		case <-timer.C:
+			cancel()
+		}
+	}()
+
+	return stepCtx, cancel
+}
+
+// killStep is a helper function to mark a
+// step that will not be executed as killed.
+func (c *client) killStep(ctn *pipeline.Container) error {
+	// update the engine step object
+	s := new(library.Step)
+	s.SetName(ctn.Name)
+	s.SetNumber(ctn.Number)
+
+	// check if the step was already planned
+	result, ok := c.steps.Load(ctn.ID)
+	if ok {
+		s = result.(*library.Step)
+	}
+
+	s.SetStatus(constants.StatusKilled)
+	s.SetFinished(time.Now().UTC().Unix())
+
+	c.logger.Infof("uploading %s step state", ctn.Name)
+	// send the step update to the Vela server
+	err := c.updateStep(s)
 	if err != nil {
 		return err
 	}
@@ -243,8 +1195,8 @@ func (c *client) ExecStep(ctx context.Context, ctn *pipeline.Container) error {
 
 // DestroyStep cleans up steps after execution.
 func (c *client) DestroyStep(ctx context.Context, ctn *pipeline.Container) error {
-	// TODO: remove hardcoded reference
-	if ctn.Name == "init" {
+	// check if the container is the init step
+	if c.isInitStep(ctn) {
 		return nil
 	}
 
@@ -253,6 +1205,12 @@ func (c *client) DestroyStep(ctx context.Context, ctn *pipeline.Container) error
 		"step": ctn.Name,
 	})
 
+	// check if the step was skipped without running a container
+	result, ok := c.steps.Load(ctn.ID)
+	if ok && result.(*library.Step).GetStatus() == statusSkipped {
+		return nil
+	}
+
 	logger.Debug("removing container")
 	// remove the runtime container
 	err := c.Runtime.RemoveContainer(ctx, ctn)
@@ -260,5 +1218,53 @@ func (c *client) DestroyStep(ctx context.Context, ctn *pipeline.Container) error
 		return err
 	}
 
+	// check if logs were streamed from the container
+	result, ok = c.streams.Load(ctn.ID)
+	if !ok {
+		return nil
+	}
+
+	c.streams.Delete(ctn.ID)
+
+	logger.Debug("waiting for logs")
+	// wait for the container logs to finish uploading
+	err = result.(*stream).wait(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to stream logs for %s step: %w", ctn.Name, err)
+	}
+
 	return nil
 }
+
+// stream tracks the logs streaming from a container.
+//
+// Waiting on the stream is safe from multiple goroutines,
+// so the step can be destroyed while it is executing.
+type stream struct {
+	done chan struct{}
+	err  error
+}
+
+// newStream returns a stream for the logs of a container.
+func newStream() *stream {
+	return &stream{done: make(chan struct{})}
+}
+
+// finish records the result of streaming the logs
+// and releases the callers waiting on the stream.
+func (s *stream) finish(err error) {
+	s.err = err
+
+	close(s.done)
+}
+
+// wait blocks until the logs have finished
+// streaming or the context is done.
+func (s *stream) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return s.err
+	}
+}
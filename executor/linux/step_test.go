@@ -5,15 +5,28 @@
 package linux
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/go-vela/mock/server"
+	"github.com/go-vela/worker/runtime"
 	"github.com/go-vela/worker/runtime/docker"
 
 	"github.com/go-vela/sdk-go/vela"
 
+	"github.com/go-vela/types/constants"
 	"github.com/go-vela/types/library"
 	"github.com/go-vela/types/pipeline"
 
@@ -90,6 +103,328 @@ func TestExecutor_CreateStep_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_CreateStep_Substitution(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup tests
+	tests := []struct {
+		lenient bool
+		command string
+		want    string
+		failure bool
+	}{
+		{lenient: false, command: "echo ${FOO} ${BAR}", want: "echo foo "},
+		{lenient: true, command: "echo ${FOO} ${BAR}", want: "echo foo ${BAR}"},
+		{lenient: false, command: "echo ${FOO", failure: true},
+		{lenient: true, command: "echo ${FOO", want: "echo ${FOO"},
+		{lenient: true, command: "echo ${BAR:-bar} ${BAR}", want: "echo bar ${BAR}"},
+		{lenient: true, command: "echo ${BAR=${FOO}} ${BAZ}", want: "echo foo ${BAZ}"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(c, r)
+		e.WithLenientSubstitution(test.lenient)
+
+		ctn := &pipeline.Container{
+			ID:          "__0_echo",
+			Environment: map[string]string{"FOO": "foo"},
+			Image:       "alpine:latest",
+			Name:        "echo",
+			Number:      1,
+			Commands:    []string{test.command},
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("CreateStep for %s should have returned err", test.command)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("CreateStep for %s returned err: %v", test.command, err)
+		}
+
+		if ctn.Commands[0] != test.want {
+			t.Errorf("CreateStep for %s is %s, want %s", test.command, ctn.Commands[0], test.want)
+		}
+	}
+}
+
+func TestExecutor_CreateStep_RequiredEnvironment(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup tests
+	tests := []struct {
+		lenient  bool
+		required string
+		command  string
+		want     string
+	}{
+		{required: "FOO", want: ""},
+		{required: "FOO, TOKEN,REGION", want: "publish step is missing required environment variables: TOKEN, REGION"},
+		{lenient: true, required: "FOO,REGION", command: "echo ${FOO", want: "publish step is missing required environment variables: REGION"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(c, r)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+		e.WithLenientSubstitution(test.lenient)
+
+		ctn := &pipeline.Container{
+			ID: "__0_publish",
+			Environment: map[string]string{
+				"FOO":       "foo",
+				"TOKEN":     "${UNSET}",
+				requiredEnv: test.required,
+			},
+			Image:    "alpine:latest",
+			Name:     "publish",
+			Number:   1,
+			Commands: []string{test.command},
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+
+		if len(test.want) > 0 {
+			if err == nil || err.Error() != test.want {
+				t.Errorf("CreateStep requiring %s returned err %v, want %s", test.required, err, test.want)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("CreateStep requiring %s returned err: %v", test.required, err)
+		}
+	}
+}
+
+func TestExecutor_CreateStep_GlobalEnvironment(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithGlobalEnvironment(map[string]string{
+		"GLOBAL": "global",
+		"FOO":    "global",
+	})
+
+	ctn := &pipeline.Container{
+		ID:          "__0_echo",
+		Environment: map[string]string{"FOO": "step"},
+		Image:       "alpine:latest",
+		Name:        "echo",
+		Number:      1,
+		Commands:    []string{"echo ${GLOBAL} ${FOO}"},
+	}
+
+	// run test
+	err := e.CreateStep(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("CreateStep returned err: %v", err)
+	}
+
+	if ctn.Environment["GLOBAL"] != "global" {
+		t.Errorf("GLOBAL is %q, want %q", ctn.Environment["GLOBAL"], "global")
+	}
+
+	if ctn.Environment["FOO"] != "step" {
+		t.Errorf("FOO is %q, want %q", ctn.Environment["FOO"], "step")
+	}
+
+	if ctn.Commands[0] != "echo global step" {
+		t.Errorf("Commands is %v, want %v", ctn.Commands[0], "echo global step")
+	}
+}
+
+func TestExecutor_CreateStep_Escape(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup tests
+	tests := []string{
+		`say "hello"`,
+		`C:\path\to\file`,
+		"line one\nline two",
+		"tab\tand carriage\r\n",
+		`{"key": "value\n"}`,
+		"<html> & more",
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(c, r)
+
+		ctn := &pipeline.Container{
+			ID:          "__0_echo",
+			Environment: map[string]string{"FOO": test},
+			Image:       "alpine:latest",
+			Name:        "echo",
+			Number:      1,
+			Commands:    []string{"echo ${FOO}"},
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+		if err != nil {
+			t.Errorf("CreateStep for %q returned err: %v", test, err)
+		}
+
+		want := "echo " + test
+
+		if ctn.Commands[0] != want {
+			t.Errorf("CreateStep for %q is %q, want %q", test, ctn.Commands[0], want)
+		}
+	}
+}
+
+func TestExecutor_CreateStep_Canceled(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &countingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:          "__0_echo",
+		Environment: map[string]string{},
+		Image:       "alpine:latest",
+		Name:        "echo",
+		Number:      1,
+		Pull:        true,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// run test
+	err := e.CreateStep(ctx, ctn)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("CreateStep returned err %v, want %v", err, context.Canceled)
+	}
+
+	if atomic.LoadInt32(&r.calls) != 0 {
+		t.Errorf("CreateStep made %d runtime calls, want 0", r.calls)
+	}
+}
+
+func TestExecutor_CreateStep_SetupError(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &setupRuntime{Engine: mock, err: errors.New("unable to pull image alpine:latest")}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu   sync.Mutex
+		logs []byte
+	)
+
+	handler := server.FakeHandler()
+
+	// capture the API calls to update the step log
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1/logs") {
+			body, _ := ioutil.ReadAll(req.Body)
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			l := new(library.Log)
+
+			err := json.Unmarshal(body, l)
+			if err != nil {
+				t.Errorf("unable to unmarshal log: %v", err)
+			}
+
+			mu.Lock()
+			logs = l.GetData()
+			mu.Unlock()
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:          "__0_test",
+		Environment: map[string]string{},
+		Image:       "alpine:latest",
+		Name:        "test",
+		Number:      1,
+	}
+
+	want := "unable to create step: unable to pull image alpine:latest\n"
+
+	// run test
+	err := e.CreateStep(context.Background(), ctn)
+	if err == nil {
+		t.Errorf("CreateStep should have returned err")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if string(logs) != want {
+		t.Errorf("CreateStep step log is %q, want %q", logs, want)
+	}
+}
+
 func TestExecutor_PlanStep_Success(t *testing.T) {
 	// setup
 	r, _ := docker.NewMock()
@@ -202,6 +537,80 @@ func TestExecutor_PlanStep_Success(t *testing.T) {
 	}
 }
 
+func TestExecutor_PlanStep_Concurrent(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	handler := server.FakeHandler()
+
+	// setup tests
+	tests := []struct {
+		method  string
+		suffix  string
+		failure bool
+	}{
+		{failure: false},
+		{method: http.MethodPut, suffix: "/steps/1", failure: true},
+		{method: http.MethodGet, suffix: "/steps/1/logs", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		test := test
+
+		// fail the API calls matching the test
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if req.Method == test.method && strings.HasSuffix(req.URL.Path, test.suffix) {
+				http.Error(w, `{"error":"unable to plan step"}`, http.StatusInternalServerError)
+				return
+			}
+
+			handler.ServeHTTP(w, req)
+		}))
+
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		ctn := &pipeline.Container{
+			ID:          "__0_clone",
+			Environment: map[string]string{},
+			Image:       "target/vela-plugins/git:1",
+			Name:        "clone",
+			Number:      1,
+		}
+
+		err := e.PlanStep(context.Background(), ctn)
+
+		s.Close()
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("PlanStep for %s %s should have returned err", test.method, test.suffix)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("PlanStep returned err: %v", err)
+		}
+
+		if _, ok := e.steps.Load(ctn.ID); !ok {
+			t.Errorf("PlanStep did not store the step")
+		}
+
+		if _, ok := e.stepLogs.Load(ctn.ID); !ok {
+			t.Errorf("PlanStep did not store the step log")
+		}
+	}
+}
+
 func TestExecutor_ExecStep_Success(t *testing.T) {
 	// setup
 	r, _ := docker.NewMock()
@@ -316,29 +725,34 @@ func TestExecutor_ExecStep_Success(t *testing.T) {
 	}
 }
 
-func TestExecutor_DestroyStep_Success(t *testing.T) {
+func TestExecutor_ExecStep_LogFailure(t *testing.T) {
 	// setup
 	r, _ := docker.NewMock()
 
 	// setup context
 	gin.SetMode(gin.TestMode)
 
-	s := httptest.NewServer(server.FakeHandler())
+	handler := server.FakeHandler()
+
+	// fail the API calls to update the step logs
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/logs") {
+			http.Error(w, `{"error":"unable to update logs"}`, http.StatusInternalServerError)
+			return
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
 	c, _ := vela.NewClient(s.URL, nil)
 
 	e, _ := New(c, r)
+	e.WithLogRetryBackoff(0)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
 	e.WithPipeline(&pipeline.Build{
 		Version: "1",
 		ID:      "__0",
-		Services: pipeline.ContainerSlice{
-			&pipeline.Container{
-				ID:          "service_org_repo_0_postgres;",
-				Environment: map[string]string{},
-				Image:       "postgres:11-alpine",
-				Name:        "postgres",
-				Ports:       []string{"5432:5432"},
-			},
-		},
 		Steps: pipeline.ContainerSlice{
 			&pipeline.Container{
 				ID:          "__0_clone",
@@ -348,40 +762,1454 @@ func TestExecutor_DestroyStep_Success(t *testing.T) {
 				Number:      1,
 				Pull:        true,
 			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, new(library.Step))
+
+	// run test
+	got := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+
+	if got == nil {
+		t.Errorf("ExecStep should have returned err")
+	}
+}
+
+func TestExecutor_Step_DryRun(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &countingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var uploaded int32
+
+	handler := server.FakeHandler()
+
+	// capture the API calls to update the step logs
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/logs") {
+			atomic.AddInt32(&uploaded, 1)
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithDryRun(true)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
 			&pipeline.Container{
-				ID:          "__0_exit",
-				Environment: map[string]string{},
+				ID:          "__0_echo",
+				Environment: map[string]string{"FOO": "bar"},
 				Image:       "alpine:latest",
-				Name:        "exit",
-				Number:      2,
+				Name:        "echo",
+				Number:      1,
 				Pull:        true,
-				Ruleset: pipeline.Ruleset{
-					Continue: true,
-				},
-				Commands: []string{"exit 1"},
+				Commands:    []string{"echo ${FOO}"},
 			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.CreateStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("CreateStep returned err: %v", err)
+	}
+
+	err = e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	if e.pipeline.Steps[0].Commands[0] != "echo bar" {
+		t.Errorf("Commands is %v, want [echo bar]", e.pipeline.Steps[0].Commands)
+	}
+
+	if got := atomic.LoadInt32(&r.calls); got != 0 {
+		t.Errorf("runtime was called %d times, want 0", got)
+	}
+
+	if got := atomic.LoadInt32(&uploaded); got != 0 {
+		t.Errorf("logs were uploaded %d times, want 0", got)
+	}
+}
+
+func TestExecutor_ExecStep_ExitCode(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &exitRuntime{Engine: mock, code: 1}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var updated int32
+
+	handler := server.FakeHandler()
+
+	// capture the API calls to update the step
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1") {
+			atomic.AddInt32(&updated, 1)
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
 			&pipeline.Container{
-				ID:          "__0_echo",
+				ID:          "__0_exit",
 				Environment: map[string]string{},
 				Image:       "alpine:latest",
-				Name:        "echo",
-				Number:      2,
+				Name:        "exit",
+				Number:      1,
 				Pull:        true,
-				Commands:    []string{"echo ${FOOBAR}"},
-				Secrets: pipeline.StepSecretSlice{
-					&pipeline.StepSecret{
-						Source: "foobar",
-						Target: "foobar",
-					},
-				},
+				Commands:    []string{"exit 1"},
 			},
 		},
 	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
 
 	// run test
-	got := e.DestroyStep(context.Background(), e.pipeline.Steps[0])
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetExitCode() != 1 {
+		t.Errorf("ExecStep exit code is %d, want 1", got.GetExitCode())
+	}
+
+	if got.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), constants.StatusFailure)
+	}
+
+	if atomic.LoadInt32(&updated) == 0 {
+		t.Errorf("ExecStep should have updated the step")
+	}
+}
+
+func TestExecutor_ExecStep_LateStart(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &lateRuntime{Engine: mock, failures: 2}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(defaultLogBufferSize * 4)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	if atomic.LoadInt32(&r.tails) != 3 {
+		t.Errorf("ExecStep tailed the container %d times, want 3", atomic.LoadInt32(&r.tails))
+	}
+
+	result, _ := e.stepLogs.Load(e.pipeline.Steps[0].ID)
+	logs := result.(*library.Log)
+
+	if len(logs.GetData()) == 0 {
+		t.Errorf("ExecStep should have captured the logs once the container was ready")
+	}
+}
+
+func TestExecutor_ExecStep_Retries(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &flakyRuntime{Engine: mock, failures: 1}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(defaultLogBufferSize * 4)
+	e.WithStepRetries(2)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_flaky",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "flaky",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	if atomic.LoadInt32(&r.runs) != 2 {
+		t.Errorf("ExecStep ran the container %d times, want 2", atomic.LoadInt32(&r.runs))
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetExitCode() != 0 {
+		t.Errorf("ExecStep exit code is %d, want 0", got.GetExitCode())
+	}
+
+	if got.GetStatus() != constants.StatusSuccess {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), constants.StatusSuccess)
+	}
+
+	result, _ = e.stepLogs.Load(e.pipeline.Steps[0].ID)
+	logs := result.(*library.Log)
+
+	marker := "[retrying: attempt 2 of 3 after exit code 1]\n"
+	if !strings.Contains(string(logs.GetData()), marker) {
+		t.Errorf("ExecStep logs are %q, want marker %q", logs.GetData(), marker)
+	}
+}
+
+func TestExecutor_Step_Skipped(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &countingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{
+		Number: vela.Int(1),
+		Branch: vela.String("feature"),
+		Event:  vela.String(constants.EventPush),
+		Status: vela.String(constants.StatusSuccess),
+	})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_publish",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "publish",
+				Number:      1,
+				Pull:        true,
+				Ruleset: pipeline.Ruleset{
+					If: pipeline.Rules{Branch: []string{"master"}},
+				},
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	// run test
+	err := e.CreateStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("CreateStep returned err: %v", err)
+	}
+
+	err = e.PlanStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("PlanStep returned err: %v", err)
+	}
+
+	err = e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	err = e.DestroyStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("DestroyStep returned err: %v", err)
+	}
+
+	// the container is set up since the ruleset
+	// is only matched once the step is executed
+	if atomic.LoadInt32(&r.calls) != 1 {
+		t.Errorf("Skipped step made %d runtime calls, want 1", atomic.LoadInt32(&r.calls))
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetStatus() != statusSkipped {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), statusSkipped)
+	}
+}
+
+func TestExecutor_Step_StatusTransitions(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var (
+		mu       sync.Mutex
+		statuses []string
+	)
+
+	handler := server.FakeHandler()
+
+	// capture the status sent by the API calls to update the step
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/steps/1") {
+			body, _ := ioutil.ReadAll(req.Body)
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+			step := new(library.Step)
+
+			err := json.Unmarshal(body, step)
+			if err != nil {
+				t.Errorf("unable to unmarshal step: %v", err)
+			}
+
+			mu.Lock()
+			statuses = append(statuses, step.GetStatus())
+			mu.Unlock()
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	want := []string{
+		constants.StatusPending,
+		constants.StatusRunning,
+		constants.StatusSuccess,
+	}
+
+	// run test
+	err := e.PlanStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("PlanStep returned err: %v", err)
+	}
+
+	err = e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if !reflect.DeepEqual(statuses, want) {
+		t.Errorf("Step statuses are %v, want %v", statuses, want)
+	}
+}
+
+func TestExecutor_ExecStep_OOMKilled(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_oomkilled",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "oomkilled",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetExitCode() != 137 {
+		t.Errorf("ExecStep exit code is %d, want 137", got.GetExitCode())
+	}
+
+	if got.GetStatus() != constants.StatusFailure {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), constants.StatusFailure)
+	}
+
+	if !strings.Contains(got.GetError(), "memory") {
+		t.Errorf("ExecStep error is %q, want it to mention the memory limit", got.GetError())
+	}
+}
+
+func TestExecutor_ExecStep_Killed(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_killed",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "killed",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetExitCode() != 137 {
+		t.Errorf("ExecStep exit code is %d, want 137", got.GetExitCode())
+	}
+
+	if got.GetStatus() != constants.StatusKilled {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), constants.StatusKilled)
+	}
+
+	if !strings.Contains(got.GetError(), "signal 9") {
+		t.Errorf("ExecStep error is %q, want it to mention the signal", got.GetError())
+	}
+}
+
+func TestExecutor_ExecStep_Timeout(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	r := &blockingRuntime{Engine: mock}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithStepTimeout(50 * time.Millisecond)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, new(library.Step))
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	if atomic.LoadInt32(&r.removed) != 1 {
+		t.Errorf("ExecStep should have removed the container")
+	}
+
+	result, _ := e.steps.Load(e.pipeline.Steps[0].ID)
+	got := result.(*library.Step)
+
+	if got.GetStatus() != constants.StatusKilled {
+		t.Errorf("ExecStep status is %s, want %s", got.GetStatus(), constants.StatusKilled)
+	}
+
+	if !strings.Contains(got.GetError(), "exceeding timeout of 50ms") {
+		t.Errorf("ExecStep error is %q, want it to mention the timeout", got.GetError())
+	}
+}
+
+func TestExecutor_ExecStep_Detach(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var got int32
+
+	s := logServer(&got)
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_postgres",
+				Detach:      true,
+				Environment: map[string]string{},
+				Image:       "postgres:11-alpine",
+				Name:        "postgres",
+				Number:      1,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, new(library.Log))
+	e.steps.Store(e.pipeline.Steps[0].ID, new(library.Step))
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	_, ok := e.streams.Load(e.pipeline.Steps[0].ID)
+	if !ok {
+		t.Errorf("ExecStep should have tracked the detached container")
+	}
+
+	err = e.DestroyStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("DestroyStep returned err: %v", err)
+	}
+
+	_, ok = e.streams.Load(e.pipeline.Steps[0].ID)
+	if ok {
+		t.Errorf("DestroyStep should have removed the detached container")
+	}
+
+	if atomic.LoadInt32(&got) == 0 {
+		t.Errorf("DestroyStep should have uploaded the detached container logs")
+	}
+}
+
+func TestExecutor_DestroyStep_Streaming(t *testing.T) {
+	// setup
+	mock, _ := docker.NewMock()
+	rc, wc := io.Pipe()
+	r := &streamRuntime{Engine: mock, rc: rc, wc: wc}
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+		},
+	})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	l := new(library.Log)
+
+	e.stepLogs.Store(e.pipeline.Steps[0].ID, l)
+	e.steps.Store(e.pipeline.Steps[0].ID, &library.Step{Number: vela.Int(1)})
+
+	// run test
+	err := e.ExecStep(context.Background(), e.pipeline.Steps[0])
+	if err == nil {
+		t.Errorf("ExecStep should have returned err")
+	}
+
+	// destroy the step while the logs are still streaming
+	err = e.DestroyStep(context.Background(), e.pipeline.Steps[0])
+	if err != nil {
+		t.Errorf("DestroyStep returned err: %v", err)
+	}
+
+	if string(l.GetData()) != "goodbye\n" {
+		t.Errorf("DestroyStep logs are %q, want %q", l.GetData(), "goodbye\n")
+	}
+}
+
+func TestExecutor_DestroyStep_Success(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithPipeline(&pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+		Services: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "service_org_repo_0_postgres;",
+				Environment: map[string]string{},
+				Image:       "postgres:11-alpine",
+				Name:        "postgres",
+				Ports:       []string{"5432:5432"},
+			},
+		},
+		Steps: pipeline.ContainerSlice{
+			&pipeline.Container{
+				ID:          "__0_clone",
+				Environment: map[string]string{},
+				Image:       "target/vela-plugins/git:1",
+				Name:        "clone",
+				Number:      1,
+				Pull:        true,
+			},
+			&pipeline.Container{
+				ID:          "__0_exit",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "exit",
+				Number:      2,
+				Pull:        true,
+				Ruleset: pipeline.Ruleset{
+					Continue: true,
+				},
+				Commands: []string{"exit 1"},
+			},
+			&pipeline.Container{
+				ID:          "__0_echo",
+				Environment: map[string]string{},
+				Image:       "alpine:latest",
+				Name:        "echo",
+				Number:      2,
+				Pull:        true,
+				Commands:    []string{"echo ${FOOBAR}"},
+				Secrets: pipeline.StepSecretSlice{
+					&pipeline.StepSecret{
+						Source: "foobar",
+						Target: "foobar",
+					},
+				},
+			},
+		},
+	})
+
+	// run test
+	got := e.DestroyStep(context.Background(), e.pipeline.Steps[0])
 
 	if got != nil {
 		t.Errorf("DestroyStep is %v, want nil", got)
 	}
 }
+
+func TestExecutor_streamStep_LogBufferSize(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup types
+	output := "hello\nhello\nhello\nhello\n"
+
+	tests := []struct {
+		size int
+		want int32
+	}{
+		{size: 0, want: 5},
+		{size: 10, want: 3},
+		{size: 1000, want: 1},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var got int32
+
+		s := logServer(&got)
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithLogBufferSize(test.size)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		ctn := &pipeline.Container{
+			ID:     "__0_clone",
+			Name:   "clone",
+			Number: 1,
+		}
+
+		err := e.streamStep(context.Background(), ctn, strings.NewReader(output), new(library.Log))
+		if err != nil {
+			t.Errorf("streamStep returned err: %v", err)
+		}
+
+		if atomic.LoadInt32(&got) != test.want {
+			t.Errorf("streamStep with size %d uploaded %d times, want %d", test.size, got, test.want)
+		}
+
+		s.Close()
+	}
+}
+
+func TestExecutor_streamStep_LogLineMaxSize(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup types
+	line := strings.Repeat("a", 100*1024)
+
+	tests := []struct {
+		size int
+		want string
+	}{
+		{size: defaultLogLineMaxSize, want: line + "\n"},
+		{size: 80 * 1024, want: line[:80*1024] + "\n" + line[80*1024:] + "\n"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var count int32
+
+		s := logServer(&count)
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithLogBufferSize(len(line) * 2)
+		e.WithLogLineMaxSize(test.size)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		ctn := &pipeline.Container{
+			ID:     "__0_clone",
+			Name:   "clone",
+			Number: 1,
+		}
+
+		l := new(library.Log)
+
+		err := e.streamStep(context.Background(), ctn, strings.NewReader(line+"\n"), l)
+		if err != nil {
+			t.Errorf("streamStep returned err: %v", err)
+		}
+
+		if string(l.GetData()) != test.want {
+			t.Errorf("streamStep with max line size %d logs are %d bytes, want %d", test.size, len(l.GetData()), len(test.want))
+		}
+
+		s.Close()
+	}
+}
+
+func TestExecutor_streamStep_ReadError(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var count int32
+
+	s := logServer(&count)
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(1024)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	want := errors.New("connection reset")
+
+	// end the output with an error after the first line
+	rc, wc := io.Pipe()
+
+	go func() {
+		_, _ = wc.Write([]byte("hello\n"))
+		_ = wc.CloseWithError(want)
+	}()
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, rc, l)
+	if !errors.Is(err, want) {
+		t.Errorf("streamStep returned err %v, want %v", err, want)
+	}
+
+	if string(l.GetData()) != "hello\n" {
+		t.Errorf("streamStep logs are %q, want %q", l.GetData(), "hello\n")
+	}
+}
+
+func TestExecutor_streamStep_LogRaw(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var count int32
+
+	s := logServer(&count)
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup types
+	want := make([]byte, 3*logChunkSize)
+
+	// fill the output with every byte value including
+	// invalid UTF-8, carriage returns and NUL bytes
+	for i := range want {
+		want[i] = byte(i % 256)
+	}
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(len(want) * 2)
+	e.WithLogRaw(true)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, bytes.NewReader(want), l)
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	if !bytes.Equal(l.GetData(), want) {
+		t.Errorf("streamStep logs are %d bytes, want %d unchanged bytes", len(l.GetData()), len(want))
+	}
+}
+
+func TestExecutor_streamStep_LogMaxSize(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup types
+	output := "hello\nhello\nhello\nhello\n"
+
+	tests := []struct {
+		size int
+		want string
+	}{
+		{size: 0, want: output},
+		{size: 12, want: "hello\nhello\n[truncated: logs exceeded max size of 12 bytes]\n"},
+		{size: 100, want: output},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var count int32
+
+		s := logServer(&count)
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithLogMaxSize(test.size)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		ctn := &pipeline.Container{
+			ID:     "__0_clone",
+			Name:   "clone",
+			Number: 1,
+		}
+
+		l := new(library.Log)
+
+		err := e.streamStep(context.Background(), ctn, strings.NewReader(output), l)
+		if err != nil {
+			t.Errorf("streamStep returned err: %v", err)
+		}
+
+		if string(l.GetData()) != test.want {
+			t.Errorf("streamStep with max size %d logs are %q, want %q", test.size, l.GetData(), test.want)
+		}
+
+		s.Close()
+	}
+}
+
+func TestExecutor_streamStep_LogStripANSI(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	// setup types
+	output := "\x1b[32mhello\x1b[0m\n"
+
+	tests := []struct {
+		strip bool
+		want  string
+	}{
+		{strip: true, want: "hello\n"},
+		{strip: false, want: output},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var count int32
+
+		s := logServer(&count)
+		c, _ := vela.NewClient(s.URL, nil)
+
+		e, _ := New(c, r)
+		e.WithLogStripANSI(test.strip)
+		e.WithBuild(&library.Build{Number: vela.Int(1)})
+		e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+		ctn := &pipeline.Container{
+			ID:     "__0_clone",
+			Name:   "clone",
+			Number: 1,
+		}
+
+		l := new(library.Log)
+
+		err := e.streamStep(context.Background(), ctn, strings.NewReader(output), l)
+		if err != nil {
+			t.Errorf("streamStep returned err: %v", err)
+		}
+
+		if string(l.GetData()) != test.want {
+			t.Errorf("streamStep with strip %t logs are %q, want %q", test.strip, l.GetData(), test.want)
+		}
+
+		s.Close()
+	}
+}
+
+func TestExecutor_streamStep_LogTimestamps(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var count int32
+
+	s := logServer(&count)
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogTimestamps(true)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	l := new(library.Log)
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, strings.NewReader("hello\nworld\n"), l)
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(l.GetData()), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("streamStep captured %d lines, want 2", len(lines))
+	}
+
+	for i, want := range []string{"hello", "world"} {
+		parts := strings.SplitN(lines[i], " ", 2)
+		if len(parts) != 2 {
+			t.Errorf("streamStep line %q should be prefixed with a timestamp", lines[i])
+
+			continue
+		}
+
+		_, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			t.Errorf("streamStep line %q timestamp is not RFC3339: %v", lines[i], err)
+		}
+
+		if parts[1] != want {
+			t.Errorf("streamStep line is %q, want %q", parts[1], want)
+		}
+	}
+}
+
+func TestExecutor_streamStep_LogFlushInterval(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	var got int32
+
+	s := logServer(&got)
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithLogBufferSize(1000)
+	e.WithLogFlushInterval(10 * time.Millisecond)
+	e.WithBuild(&library.Build{Number: vela.Int(1)})
+	e.WithRepo(&library.Repo{Org: vela.String("github"), Name: vela.String("octocat")})
+
+	ctn := &pipeline.Container{
+		ID:     "__0_clone",
+		Name:   "clone",
+		Number: 1,
+	}
+
+	// setup a slow container output
+	rc, wc := io.Pipe()
+	go func() {
+		_, _ = wc.Write([]byte("hello\n"))
+
+		time.Sleep(100 * time.Millisecond)
+		wc.Close()
+	}()
+
+	// run test
+	err := e.streamStep(context.Background(), ctn, rc, new(library.Log))
+	if err != nil {
+		t.Errorf("streamStep returned err: %v", err)
+	}
+
+	// one upload from the interval and one final upload
+	if atomic.LoadInt32(&got) != 2 {
+		t.Errorf("streamStep uploaded %d times, want 2", got)
+	}
+}
+
+func TestExecutor_Step_InitStep(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	e, _ := New(c, r)
+	e.WithInitStep("bootstrap")
+
+	// replace the runtime to ensure the init step never reaches it
+	e.Runtime = &namedRuntime{name: "docker"}
+
+	ctn := &pipeline.Container{
+		ID:          "__0_bootstrap",
+		Environment: map[string]string{},
+		Image:       "#init",
+		Name:        "bootstrap",
+		Number:      1,
+	}
+
+	// run test
+	err := e.CreateStep(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("CreateStep returned err: %v", err)
+	}
+
+	err = e.ExecStep(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("ExecStep returned err: %v", err)
+	}
+
+	err = e.DestroyStep(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("DestroyStep returned err: %v", err)
+	}
+}
+
+func TestExecutor_CreateStep_Runtime(t *testing.T) {
+	// setup
+	r, _ := docker.NewMock()
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	s := httptest.NewServer(server.FakeHandler())
+	defer s.Close()
+
+	c, _ := vela.NewClient(s.URL, nil)
+
+	// setup tests
+	tests := []struct {
+		runtime runtime.Engine
+		want    string
+	}{
+		{runtime: r, want: "docker"},
+		{runtime: &namedRuntime{Engine: r, name: "kubernetes"}, want: "kubernetes"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		e, _ := New(c, test.runtime)
+
+		ctn := &pipeline.Container{
+			ID:          "__0_clone",
+			Environment: map[string]string{},
+			Image:       "target/vela-plugins/git:1",
+			Name:        "clone",
+			Number:      1,
+			Pull:        true,
+		}
+
+		err := e.CreateStep(context.Background(), ctn)
+		if err != nil {
+			t.Errorf("CreateStep returned err: %v", err)
+		}
+
+		got := ctn.Environment["VELA_RUNTIME"]
+
+		if got != test.want {
+			t.Errorf("VELA_RUNTIME is %v, want %v", got, test.want)
+		}
+	}
+}
+
+// namedRuntime is a runtime with the provided name.
+type namedRuntime struct {
+	runtime.Engine
+
+	name string
+}
+
+// Name returns the provided name of the runtime.
+func (r *namedRuntime) Name() string {
+	return r.name
+}
+
+// blockingRuntime is a runtime that blocks waiting
+// on a container until the context is done.
+type blockingRuntime struct {
+	runtime.Engine
+
+	removed int32
+}
+
+// WaitContainer blocks until the context is done.
+func (r *blockingRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+// RemoveContainer records the container was removed.
+func (r *blockingRuntime) RemoveContainer(ctx context.Context, ctn *pipeline.Container) error {
+	atomic.AddInt32(&r.removed, 1)
+
+	return nil
+}
+
+// logServer is a helper function to create a test server
+// that counts the API calls to update the step logs.
+func logServer(count *int32) *httptest.Server {
+	handler := server.FakeHandler()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPut && strings.HasSuffix(req.URL.Path, "/logs") {
+			atomic.AddInt32(count, 1)
+		}
+
+		handler.ServeHTTP(w, req)
+	}))
+}
+
+// exitRuntime is a runtime that sets the
+// exit code when inspecting a container.
+type exitRuntime struct {
+	runtime.Engine
+
+	code int
+}
+
+// InspectContainer sets the exit code for the container.
+func (r *exitRuntime) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
+	ctn.ExitCode = r.code
+
+	return nil
+}
+
+// lateRuntime is a runtime that is unable to tail a
+// container until it has been tailed a number of times.
+type lateRuntime struct {
+	runtime.Engine
+
+	failures int32
+	tails    int32
+}
+
+// TailContainer returns an error until the container is ready.
+func (r *lateRuntime) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
+	if atomic.AddInt32(&r.tails, 1) <= r.failures {
+		return nil, errors.New("container is not running")
+	}
+
+	return r.Engine.TailContainer(ctx, ctn)
+}
+
+// flakyRuntime is a runtime that sets a non-zero
+// exit code for the first runs of a container.
+type flakyRuntime struct {
+	runtime.Engine
+
+	failures int32
+	runs     int32
+}
+
+// RunContainer counts the run and runs the container.
+func (r *flakyRuntime) RunContainer(ctx context.Context, b *pipeline.Build, ctn *pipeline.Container) error {
+	atomic.AddInt32(&r.runs, 1)
+
+	return r.Engine.RunContainer(ctx, b, ctn)
+}
+
+// InspectContainer sets the exit code for the run of the container.
+func (r *flakyRuntime) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
+	ctn.ExitCode = 0
+
+	if atomic.LoadInt32(&r.runs) <= r.failures {
+		ctn.ExitCode = 1
+	}
+
+	return nil
+}
+
+// countingRuntime is a runtime that counts
+// the calls made to set up and run a container.
+type countingRuntime struct {
+	runtime.Engine
+
+	calls int32
+}
+
+// SetupContainer counts the call and sets up the container.
+func (r *countingRuntime) SetupContainer(ctx context.Context, ctn *pipeline.Container) error {
+	atomic.AddInt32(&r.calls, 1)
+
+	return r.Engine.SetupContainer(ctx, ctn)
+}
+
+// RunContainer counts the call and runs the container.
+func (r *countingRuntime) RunContainer(ctx context.Context, b *pipeline.Build, ctn *pipeline.Container) error {
+	atomic.AddInt32(&r.calls, 1)
+
+	return r.Engine.RunContainer(ctx, b, ctn)
+}
+
+// TailContainer counts the call and tails the container.
+func (r *countingRuntime) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
+	atomic.AddInt32(&r.calls, 1)
+
+	return r.Engine.TailContainer(ctx, ctn)
+}
+
+// WaitContainer counts the call and waits on the container.
+func (r *countingRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	atomic.AddInt32(&r.calls, 1)
+
+	return r.Engine.WaitContainer(ctx, ctn)
+}
+
+// setupRuntime is a runtime that fails
+// to set up the container with the error.
+type setupRuntime struct {
+	runtime.Engine
+
+	err error
+}
+
+// SetupContainer returns the error for the container.
+func (r *setupRuntime) SetupContainer(ctx context.Context, ctn *pipeline.Container) error {
+	return r.err
+}
+
+// streamRuntime is a runtime that fails waiting on the
+// container and streams the logs until it is removed.
+type streamRuntime struct {
+	runtime.Engine
+
+	rc *io.PipeReader
+	wc *io.PipeWriter
+}
+
+// TailContainer returns the logs for the container.
+func (r *streamRuntime) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
+	return r.rc, nil
+}
+
+// WaitContainer returns an error for the container.
+func (r *streamRuntime) WaitContainer(ctx context.Context, ctn *pipeline.Container) error {
+	return errors.New("unable to wait for container")
+}
+
+// RemoveContainer writes the last logs and ends the stream.
+func (r *streamRuntime) RemoveContainer(ctx context.Context, ctn *pipeline.Container) error {
+	go func() {
+		_, _ = r.wc.Write([]byte("goodbye\n"))
+		r.wc.Close()
+	}()
+
+	return nil
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package executor
+
+import (
+	"time"
+)
+
+// BuildSummary represents the result of
+// a build once it has been executed.
+type BuildSummary struct {
+	Repo     string        `json:"repo"`
+	Number   int           `json:"number"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Steps    []StepSummary `json:"steps"`
+}
+
+// StepSummary represents the result of
+// a step once it has been executed.
+type StepSummary struct {
+	Name     string        `json:"name"`
+	Number   int           `json:"number"`
+	Status   string        `json:"status"`
+	ExitCode int           `json:"exit_code"`
+	Duration time.Duration `json:"duration"`
+}
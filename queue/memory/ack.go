@@ -0,0 +1,69 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+)
+
+// pending represents an item popped from the queue
+// that has not been acknowledged or returned.
+type pending struct {
+	channel string
+	data    []byte
+}
+
+// Ack removes the item popped from the queue once it is processed.
+func (c *client) Ack(ctx context.Context, item *types.Item) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return fmt.Errorf("unable to ack item from queue: %w", queue.ErrClosed)
+	}
+
+	// check if the item was popped from the queue
+	if _, ok := c.pending[item]; !ok {
+		return fmt.Errorf("unable to find item popped from queue")
+	}
+
+	delete(c.pending, item)
+
+	return nil
+}
+
+// Nack returns the item popped from the queue to
+// the end of its channel to be processed again.
+func (c *client) Nack(ctx context.Context, item *types.Item) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return fmt.Errorf("unable to nack item to queue: %w", queue.ErrClosed)
+	}
+
+	// check if the item was popped from the queue
+	p, ok := c.pending[item]
+	if !ok {
+		return fmt.Errorf("unable to find item popped from queue")
+	}
+
+	delete(c.pending, item)
+
+	// push the item to the end of the channel
+	c.items[p.channel] = append(c.items[p.channel], p.data)
+
+	// notify the callers waiting for an item
+	close(c.ready)
+	c.ready = make(chan struct{})
+
+	return nil
+}
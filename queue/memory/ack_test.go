@@ -0,0 +1,86 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-vela/types"
+)
+
+func TestMemory_Ack(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	err := c.Push(context.Background(), "vela", []byte(`{"build":{"number":1}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	err = c.Ack(context.Background(), item)
+	if err != nil {
+		t.Errorf("Ack returned err: %v", err)
+	}
+
+	if len(c.pending) != 0 {
+		t.Errorf("Ack should have removed the pending item")
+	}
+
+	length, _ := c.Length(context.Background(), "vela")
+	if length != 0 {
+		t.Errorf("Length is %v, want 0", length)
+	}
+
+	err = c.Ack(context.Background(), item)
+	if err == nil {
+		t.Errorf("Ack should have returned err for an acknowledged item")
+	}
+}
+
+func TestMemory_Nack(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	err := c.Push(context.Background(), "vela", []byte(`{"build":{"number":1}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	err = c.Nack(context.Background(), item)
+	if err != nil {
+		t.Errorf("Nack returned err: %v", err)
+	}
+
+	got, channel, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 1)
+	}
+
+	if channel != "vela" {
+		t.Errorf("Pop channel is %v, want %v", channel, "vela")
+	}
+
+	err = c.Nack(context.Background(), new(types.Item))
+	if err == nil {
+		t.Errorf("Nack should have returned err for an item not popped")
+	}
+}
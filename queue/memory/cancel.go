@@ -0,0 +1,83 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Cancel notifies the listeners in the queue
+// to cancel the specified build.
+func (c *client) Cancel(ctx context.Context, build string) error {
+	// check if the context is done before canceling
+	if ctx.Err() != nil {
+		return fmt.Errorf("unable to cancel build %s: %w", build, ctx.Err())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return fmt.Errorf("unable to cancel build %s: %w", build, queue.ErrClosed)
+	}
+
+	// notify the listeners for the build
+	for _, canceled := range c.cancels[build] {
+		close(canceled)
+	}
+
+	delete(c.cancels, build)
+
+	return nil
+}
+
+// Canceled returns a channel closed when a
+// cancel is received for the specified build.
+func (c *client) Canceled(ctx context.Context, build string) (<-chan struct{}, error) {
+	canceled := make(chan struct{})
+
+	c.mu.Lock()
+
+	// check if the queue was closed
+	if c.closed {
+		c.mu.Unlock()
+
+		return nil, fmt.Errorf("unable to subscribe to cancels for build %s: %w", build, queue.ErrClosed)
+	}
+
+	c.cancels[build] = append(c.cancels[build], canceled)
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-canceled:
+			return
+		case <-ctx.Done():
+		}
+
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		// remove the listener for the build
+		for i, listener := range c.cancels[build] {
+			if listener == canceled {
+				c.cancels[build] = append(c.cancels[build][:i], c.cancels[build][i+1:]...)
+
+				break
+			}
+		}
+
+		// clean up the build once all listeners are removed
+		if len(c.cancels[build]) == 0 {
+			delete(c.cancels, build)
+		}
+	}()
+
+	return canceled, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_Cancel(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	canceled, err := c.Canceled(ctx, "github/octocat/1")
+	if err != nil {
+		t.Errorf("Canceled returned err: %v", err)
+	}
+
+	other, err := c.Canceled(ctx, "github/octocat/2")
+	if err != nil {
+		t.Errorf("Canceled returned err: %v", err)
+	}
+
+	// run test
+	err = c.Cancel(context.Background(), "github/octocat/1")
+	if err != nil {
+		t.Errorf("Cancel returned err: %v", err)
+	}
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Errorf("Canceled should have been closed for the canceled build")
+	}
+
+	select {
+	case <-other:
+		t.Errorf("Canceled should not have been closed for another build")
+	default:
+	}
+}
+
+func TestMemory_Canceled_Done(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	_, err := c.Canceled(ctx, "github/octocat/1")
+	if err != nil {
+		t.Errorf("Canceled returned err: %v", err)
+	}
+
+	// run test
+	cancel()
+
+	// wait for the listener to be removed
+	for i := 0; i < 100; i++ {
+		c.mu.Lock()
+		got := len(c.cancels)
+		c.mu.Unlock()
+
+		if got == 0 {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Errorf("Canceled should have removed the listener once the context is done")
+}
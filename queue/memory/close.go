@@ -0,0 +1,37 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+)
+
+// Close closes the in-memory queue and releases
+// the items and listeners held by the queue.
+//
+// Any operation on the queue once it is
+// closed returns queue.ErrClosed.
+func (c *client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was already closed
+	if c.closed {
+		return queue.ErrClosed
+	}
+
+	c.closed = true
+
+	// release the items and listeners for the queue
+	c.items = make(map[string][][]byte)
+	c.pending = make(map[*types.Item]pending)
+	c.cancels = make(map[string][]chan struct{})
+
+	// notify the callers waiting for an item
+	close(c.ready)
+
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-vela/worker/queue"
+)
+
+func TestMemory_Close(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	errs := make(chan error, 1)
+
+	// pop from the queue while it is empty
+	go func() {
+		_, _, err := c.Pop(context.Background())
+
+		errs <- err
+	}()
+
+	// run test
+	err := c.Close()
+	if err != nil {
+		t.Errorf("Close returned err: %v", err)
+	}
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, queue.ErrClosed) {
+			t.Errorf("Pop returned err %v, want %v", err, queue.ErrClosed)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("Pop did not return after Close")
+	}
+
+	err = c.Push(context.Background(), "vela", []byte("foo"))
+	if !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Push returned err %v, want %v", err, queue.ErrClosed)
+	}
+
+	_, err = c.Length(context.Background(), "vela")
+	if !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Length returned err %v, want %v", err, queue.ErrClosed)
+	}
+
+	err = c.Ping(context.Background())
+	if !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Ping returned err %v, want %v", err, queue.ErrClosed)
+	}
+
+	err = c.Close()
+	if !errors.Is(err, queue.ErrClosed) {
+		t.Errorf("Close returned err %v, want %v", err, queue.ErrClosed)
+	}
+}
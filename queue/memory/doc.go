@@ -0,0 +1,12 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package memory provides the ability for Vela to
+// use an in-memory queue backend for tests and
+// single node deployments.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/worker/queue/memory"
+package memory
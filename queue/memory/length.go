@@ -0,0 +1,26 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Length returns the number of items pending in
+// the specified channel in the queue.
+func (c *client) Length(ctx context.Context, channel string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return 0, fmt.Errorf("unable to get length of queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	return int64(len(c.items[channel])), nil
+}
@@ -0,0 +1,47 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/go-vela/types"
+)
+
+// Driver defines the name of the in-memory queue driver.
+const Driver = "memory"
+
+type client struct {
+	Channels []string
+
+	// private fields
+	mu      sync.Mutex
+	items   map[string][][]byte
+	pending map[*types.Item]pending
+	ready   chan struct{}
+	cancels map[string][]chan struct{}
+	closed  bool
+}
+
+// New returns a Queue implementation that
+// integrates with an in-memory queue.
+func New(channels []string) (*client, error) {
+	// immediately return if no channels are provided
+	if len(channels) == 0 {
+		return nil, fmt.Errorf("no channels provided to queue")
+	}
+
+	// create the client object
+	c := &client{
+		Channels: channels,
+		items:    make(map[string][][]byte),
+		pending:  make(map[*types.Item]pending),
+		ready:    make(chan struct{}),
+		cancels:  make(map[string][]chan struct{}),
+	}
+
+	return c, nil
+}
@@ -0,0 +1,34 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"testing"
+)
+
+func TestMemory_New(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		channels []string
+		failure  bool
+	}{
+		{channels: []string{"vela"}, failure: false},
+		{channels: []string{}, failure: true},
+		{channels: nil, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_, err := New(test.channels)
+
+		if test.failure && err == nil {
+			t.Errorf("New for %v should have returned err", test.channels)
+		}
+
+		if !test.failure && err != nil {
+			t.Errorf("New for %v returned err: %v", test.channels, err)
+		}
+	}
+}
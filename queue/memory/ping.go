@@ -0,0 +1,27 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Ping verifies the in-memory queue is healthy.
+//
+// The in-memory queue is available until it is closed.
+func (c *client) Ping(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return fmt.Errorf("unable to ping in-memory queue: %w", queue.ErrClosed)
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+)
+
+// Pop grabs an item from the specified channels off the queue
+// and returns it with the channel it was grabbed from.
+//
+// The channels are checked in the order they are provided so
+// earlier channels take priority. If no channels are provided,
+// the channels configured for the client are used.
+//
+// The pop blocks until an item is available or the
+// context provided is done. The item is held until it
+// is acknowledged with Ack or returned with Nack.
+func (c *client) Pop(ctx context.Context, channels ...string) (*types.Item, string, error) {
+	// check if any channels are provided
+	if len(channels) == 0 {
+		channels = c.Channels
+	}
+
+	for {
+		// grab the next item or the channel to wait on
+		result, channel, ready, err := c.next(channels)
+		if err != nil {
+			return nil, "", fmt.Errorf("unable to pop item from queue: %w", err)
+		}
+
+		if result != nil {
+			item := new(types.Item)
+			// unmarshal result into queue item
+			err := json.Unmarshal(result, item)
+			if err != nil {
+				return nil, "", fmt.Errorf("unable to unmarshal item from queue: %w", err)
+			}
+
+			// track the item until it is acknowledged
+			c.mu.Lock()
+			c.pending[item] = pending{channel: channel, data: result}
+			c.mu.Unlock()
+
+			return item, channel, nil
+		}
+
+		// wait for an item to be pushed
+		select {
+		case <-ctx.Done():
+			return nil, "", fmt.Errorf("unable to pop item from queue: %w", ctx.Err())
+		case <-ready:
+		}
+	}
+}
+
+// next is a helper function to remove the first item
+// from the channels and return it with its channel. If
+// no item is available, the channel closed on the next
+// push is returned.
+func (c *client) next(channels []string) ([]byte, string, <-chan struct{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return nil, "", nil, queue.ErrClosed
+	}
+
+	for _, channel := range channels {
+		items := c.items[channel]
+
+		// check if the channel has an item
+		if len(items) == 0 {
+			continue
+		}
+
+		// remove the item from the front of the channel
+		c.items[channel] = items[1:]
+
+		return items[0], channel, nil, nil
+	}
+
+	return nil, "", c.ready, nil
+}
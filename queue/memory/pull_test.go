@@ -0,0 +1,152 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestMemory_Pop_Order(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela", "linux"})
+
+	// push items to the lower priority channel first
+	items := []struct {
+		channel string
+		number  int
+	}{
+		{channel: "linux", number: 4},
+		{channel: "vela", number: 1},
+		{channel: "vela", number: 2},
+		{channel: "linux", number: 5},
+		{channel: "vela", number: 3},
+	}
+
+	for _, item := range items {
+		err := c.Push(context.Background(), item.channel, []byte(fmt.Sprintf(`{"build":{"number":%d}}`, item.number)))
+		if err != nil {
+			t.Fatalf("Push returned err: %v", err)
+		}
+	}
+
+	// run test
+	for want := 1; want <= len(items); want++ {
+		got, channel, err := c.Pop(context.Background())
+		if err != nil {
+			t.Errorf("Pop returned err: %v", err)
+		}
+
+		if got.Build.GetNumber() != want {
+			t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), want)
+		}
+
+		// the first channel is drained before the second
+		wantChannel := "vela"
+		if want > 3 {
+			wantChannel = "linux"
+		}
+
+		if channel != wantChannel {
+			t.Errorf("Pop channel is %v, want %v", channel, wantChannel)
+		}
+	}
+}
+
+func TestMemory_Pop_Channels(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	err := c.Push(context.Background(), "vela", []byte(`{"build":{"number":1}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	err = c.Push(context.Background(), "linux", []byte(`{"build":{"number":2}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	// run test
+	got, channel, err := c.Pop(context.Background(), "linux", "vela")
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 2 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 2)
+	}
+
+	if channel != "linux" {
+		t.Errorf("Pop channel is %v, want %v", channel, "linux")
+	}
+}
+
+func TestMemory_Pop_Blocking(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	// push an item while waiting on an empty queue
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		_ = c.Push(context.Background(), "vela", []byte(`{"build":{"number":1}}`))
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// run test
+	got, _, err := c.Pop(ctx)
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 1)
+	}
+}
+
+func TestMemory_Pop_Canceled(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// cancel the context while waiting on an empty queue
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	// run test
+	got, _, err := c.Pop(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Pop returned err %v, want %v", err, context.Canceled)
+	}
+
+	if got != nil {
+		t.Errorf("Pop is %v, want nil", got)
+	}
+}
+
+func TestMemory_Pop_Invalid(t *testing.T) {
+	// setup types
+	c, _ := New([]string{"vela"})
+
+	err := c.Push(context.Background(), "vela", []byte("foo"))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	// run test
+	_, _, err = c.Pop(context.Background())
+	if err == nil {
+		t.Errorf("Pop should have returned err")
+	}
+}
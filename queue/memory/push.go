@@ -0,0 +1,37 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Push inserts an item to the specified channel in the queue.
+func (c *client) Push(ctx context.Context, channel string, item []byte) error {
+	// check if the context is done before pushing
+	if ctx.Err() != nil {
+		return fmt.Errorf("unable to push item to queue channel %s: %w", channel, ctx.Err())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return fmt.Errorf("unable to push item to queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	// push a copy of the item to the end of the channel
+	c.items[channel] = append(c.items[channel], append([]byte(nil), item...))
+
+	// notify the callers waiting for an item
+	close(c.ready)
+	c.ready = make(chan struct{})
+
+	return nil
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+)
+
+// Remove removes the items pending in the specified channel
+// in the queue that match the predicate and returns the
+// number of items removed.
+//
+// Items that are unable to be unmarshaled are left in the channel.
+func (c *client) Remove(ctx context.Context, channel string, match func(*types.Item) bool) (int64, error) {
+	// check if the context is done before removing
+	if ctx.Err() != nil {
+		return 0, fmt.Errorf("unable to remove items from queue channel %s: %w", channel, ctx.Err())
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// check if the queue was closed
+	if c.closed {
+		return 0, fmt.Errorf("unable to remove items from queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	var (
+		kept    [][]byte
+		removed int64
+	)
+
+	for _, data := range c.items[channel] {
+		item := new(types.Item)
+
+		// unmarshal data into queue item
+		err := json.Unmarshal(data, item)
+		if err == nil && match(item) {
+			removed++
+
+			continue
+		}
+
+		kept = append(kept, data)
+	}
+
+	c.items[channel] = kept
+
+	return removed, nil
+}
@@ -5,12 +5,64 @@
 package queue
 
 import (
+	"context"
+	"errors"
+
 	"github.com/go-vela/types"
 )
 
+// ErrClosed defines the error returned by
+// the queue for operations once it is closed.
+var ErrClosed = errors.New("queue is closed")
+
 // Service represents the interface for Vela integrating
 // with the different supported Queue backends.
 type Service interface {
-	// Pop defines a function that grabs an item off the queue.
-	Pop() (*types.Item, error)
+	// Pop defines a function that grabs an item off the provided
+	// channels in the queue, in priority order, and returns the
+	// channel it was grabbed from. If no channels are provided,
+	// the channels configured for the queue are used.
+	//
+	// The item is held by the queue until it is
+	// acknowledged with Ack or returned with Nack.
+	Pop(context.Context, ...string) (*types.Item, string, error)
+
+	// Ack defines a function that removes an item
+	// popped from the queue once it is processed.
+	Ack(context.Context, *types.Item) error
+
+	// Nack defines a function that returns an item popped
+	// from the queue to its channel to be processed again.
+	Nack(context.Context, *types.Item) error
+
+	// Push defines a function that inserts an item to the
+	// specified channel in the queue.
+	Push(context.Context, string, []byte) error
+
+	// Remove defines a function that removes the items pending
+	// in the specified channel in the queue that match the
+	// predicate and returns the number of items removed.
+	Remove(context.Context, string, func(*types.Item) bool) (int64, error)
+
+	// Length defines a function that returns the number of
+	// items pending in the specified channel in the queue.
+	Length(context.Context, string) (int64, error)
+
+	// Ping defines a function that verifies
+	// the queue is healthy.
+	Ping(context.Context) error
+
+	// Cancel defines a function that notifies the workers
+	// listening to the queue to cancel the specified build.
+	Cancel(context.Context, string) error
+
+	// Canceled defines a function that returns a channel
+	// closed when a cancel is received for the specified
+	// build. The listener is stopped when the context is done.
+	Canceled(context.Context, string) (<-chan struct{}, error)
+
+	// Close defines a function that closes the connection
+	// to the queue and releases its resources. Operations
+	// on the queue return ErrClosed once it is closed.
+	Close() error
 }
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+
+	"github.com/go-redis/redis"
+)
+
+// pending represents an item popped from the queue
+// that has not been acknowledged or returned.
+type pending struct {
+	channel string
+	entry   string
+	id      string
+}
+
+// Ack removes the item popped from the queue from the
+// processing list for its channel once it is processed.
+func (c *client) Ack(ctx context.Context, item *types.Item) error {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return fmt.Errorf("unable to ack item from queue: %w", queue.ErrClosed)
+	}
+
+	p, err := c.popped(item)
+	if err != nil {
+		return err
+	}
+
+	// remove the item from processing
+	_, err = c.Queue.WithContext(ctx).TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.LRem(processing(p.channel), 1, p.entry)
+		pipe.ZRem(claims(p.channel), p.id)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to ack item from queue channel %s: %w", p.channel, err)
+	}
+
+	c.pending.Delete(item)
+
+	return nil
+}
+
+// Nack returns the item popped from the queue to the
+// end of its channel so another worker processes it.
+func (c *client) Nack(ctx context.Context, item *types.Item) error {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return fmt.Errorf("unable to nack item to queue: %w", queue.ErrClosed)
+	}
+
+	p, err := c.popped(item)
+	if err != nil {
+		return err
+	}
+
+	// move the item from processing back to the channel
+	_, err = c.Queue.WithContext(ctx).TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.LRem(processing(p.channel), 1, p.entry)
+		pipe.ZRem(claims(p.channel), p.id)
+		pipe.RPush(p.channel, p.entry)
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to nack item to queue channel %s: %w", p.channel, err)
+	}
+
+	c.pending.Delete(item)
+
+	return nil
+}
+
+// popped is a helper function to return the
+// pending item that was popped from the queue.
+func (c *client) popped(item *types.Item) (*pending, error) {
+	result, ok := c.pending.Load(item)
+	if !ok {
+		return nil, fmt.Errorf("unable to find item popped from queue")
+	}
+
+	return result.(*pending), nil
+}
+
+// processing is a helper function to create the name of
+// the list holding the items popped from the channel.
+func processing(channel string) string {
+	return fmt.Sprintf("%s:processing", channel)
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-vela/types"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Ack(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	processing, _ := s.List("vela:processing")
+	if len(processing) != 1 {
+		t.Errorf("Pop processing is %v, want 1 item", processing)
+	}
+
+	// run test
+	err = c.Ack(context.Background(), item)
+	if err != nil {
+		t.Errorf("Ack returned err: %v", err)
+	}
+
+	if s.Exists("vela:processing") {
+		t.Errorf("Ack should have removed the item from processing")
+	}
+
+	length, _ := c.Length(context.Background(), "vela")
+	if length != 0 {
+		t.Errorf("Length is %v, want 0", length)
+	}
+
+	err = c.Ack(context.Background(), item)
+	if err == nil {
+		t.Errorf("Ack should have returned err for an acknowledged item")
+	}
+}
+
+func TestRedis_Nack(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	err = c.Nack(context.Background(), item)
+	if err != nil {
+		t.Errorf("Nack returned err: %v", err)
+	}
+
+	if s.Exists("vela:processing") {
+		t.Errorf("Nack should have removed the item from processing")
+	}
+
+	got, channel, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 1)
+	}
+
+	if channel != "vela" {
+		t.Errorf("Pop channel is %v, want %v", channel, "vela")
+	}
+
+	err = c.Nack(context.Background(), new(types.Item))
+	if err == nil {
+		t.Errorf("Nack should have returned err for an item not popped")
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Cancel publishes a cancel for the specified build
+// to the workers subscribed to the Redis queue.
+func (c *client) Cancel(ctx context.Context, build string) error {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return fmt.Errorf("unable to cancel build %s: %w", build, queue.ErrClosed)
+	}
+
+	// send publish request to the queue
+	err := c.Queue.WithContext(ctx).Publish(cancelChannel(build), build).Err()
+	if err != nil {
+		return fmt.Errorf("unable to cancel build %s: %w", build, err)
+	}
+
+	return nil
+}
+
+// Canceled subscribes to the cancels published for the
+// specified build and returns a channel closed when one
+// is received.
+func (c *client) Canceled(ctx context.Context, build string) (<-chan struct{}, error) {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return nil, fmt.Errorf("unable to subscribe to cancels for build %s: %w", build, queue.ErrClosed)
+	}
+
+	// subscribe to the cancels for the build
+	sub := c.Queue.Subscribe(cancelChannel(build))
+
+	// wait for the subscription to be created
+	_, err := sub.Receive()
+	if err != nil {
+		sub.Close()
+
+		return nil, fmt.Errorf("unable to subscribe to cancels for build %s: %w", build, err)
+	}
+
+	canceled := make(chan struct{})
+
+	go func() {
+		defer sub.Close()
+
+		select {
+		case <-sub.Channel():
+			close(canceled)
+		case <-ctx.Done():
+		}
+	}()
+
+	return canceled, nil
+}
+
+// cancelChannel is a helper function to create the
+// pub/sub channel the cancels for the build are sent on.
+func cancelChannel(build string) string {
+	return fmt.Sprintf("cancel:%s", build)
+}
@@ -0,0 +1,38 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Close closes the connections to the Redis queue
+// and releases the resources held by the client.
+//
+// Any operation on the queue once it is
+// closed returns queue.ErrClosed.
+func (c *client) Close() error {
+	// check if the client was already closed
+	if !atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		return queue.ErrClosed
+	}
+
+	// close the connection pool for the queue
+	err := c.Queue.Close()
+	if err != nil {
+		return fmt.Errorf("unable to close Redis queue: %w", err)
+	}
+
+	return nil
+}
+
+// isClosed is a helper function to check
+// if the client for the queue was closed.
+func (c *client) isClosed() bool {
+	return atomic.LoadInt32(&c.closed) == 1
+}
@@ -0,0 +1,124 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Close(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	err = c.Push(context.Background(), "vela", []byte(`{"build":{"number":1}}`))
+	if err != nil {
+		t.Fatalf("Push returned err: %v", err)
+	}
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	err = c.Close()
+	if err != nil {
+		t.Errorf("Close returned err: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		fn   func(context.Context) error
+	}{
+		{
+			name: "Pop",
+			fn: func(ctx context.Context) error {
+				_, _, err := c.Pop(ctx)
+				return err
+			},
+		},
+		{
+			name: "Ack",
+			fn: func(ctx context.Context) error {
+				return c.Ack(ctx, item)
+			},
+		},
+		{
+			name: "Nack",
+			fn: func(ctx context.Context) error {
+				return c.Nack(ctx, item)
+			},
+		},
+		{
+			name: "Push",
+			fn: func(ctx context.Context) error {
+				return c.Push(ctx, "vela", []byte(`{"build":{"number":2}}`))
+			},
+		},
+		{
+			name: "Remove",
+			fn: func(ctx context.Context) error {
+				_, err := c.Remove(ctx, "vela", func(*types.Item) bool { return true })
+				return err
+			},
+		},
+		{
+			name: "Length",
+			fn: func(ctx context.Context) error {
+				_, err := c.Length(ctx, "vela")
+				return err
+			},
+		},
+		{
+			name: "Ping",
+			fn: func(ctx context.Context) error {
+				return c.Ping(ctx)
+			},
+		},
+		{
+			name: "Cancel",
+			fn: func(ctx context.Context) error {
+				return c.Cancel(ctx, "github/octocat/1")
+			},
+		},
+		{
+			name: "Canceled",
+			fn: func(ctx context.Context) error {
+				_, err := c.Canceled(ctx, "github/octocat/1")
+				return err
+			},
+		},
+		{
+			name: "Close",
+			fn: func(ctx context.Context) error {
+				return c.Close()
+			},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.fn(context.Background())
+		if !errors.Is(err, queue.ErrClosed) {
+			t.Errorf("%s after Close returned err %v, want %v", test.name, err, queue.ErrClosed)
+		}
+	}
+}
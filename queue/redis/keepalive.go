@@ -0,0 +1,77 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// startKeepalive is a helper function to start pinging
+// the idle connections in the pool on the configured
+// interval if a keepalive interval was provided.
+func (c *client) startKeepalive() {
+	// check if the keepalive is disabled
+	if c.KeepaliveInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.KeepaliveInterval)
+
+	go func() {
+		defer ticker.Stop()
+
+		c.keepalive(ticker.C)
+	}()
+}
+
+// keepalive is a helper function to ping the idle connections
+// in the pool on every tick so they are not dropped by the
+// proxies or load balancers between the worker and the queue.
+//
+// The keepalive stops once the queue client is closed.
+func (c *client) keepalive(ticks <-chan time.Time) {
+	for range ticks {
+		// check if the queue client was closed
+		if c.isClosed() {
+			return
+		}
+
+		// capture the number of idle connections in the pool
+		idle := int(c.Queue.PoolStats().IdleConns)
+
+		errs := make(chan error, idle)
+
+		var wg sync.WaitGroup
+
+		// send a ping request concurrently for each idle
+		// connection so every connection is checked out
+		for i := 0; i < idle; i++ {
+			wg.Add(1)
+
+			go func() {
+				defer wg.Done()
+
+				errs <- c.Queue.Ping().Err()
+			}()
+		}
+
+		wg.Wait()
+		close(errs)
+
+		// check if the queue client was closed while pinging
+		if c.isClosed() {
+			return
+		}
+
+		for err := range errs {
+			if err != nil {
+				logrus.Warnf("unable to ping idle queue connection: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Keepalive(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		ticks  int
+		closed bool
+	}{
+		{ticks: 3},
+		{ticks: 0},
+		{ticks: 3, closed: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		s, err := miniredis.Run()
+		if err != nil {
+			t.Fatalf("unable to create miniredis: %v", err)
+		}
+
+		c, err := New("redis://"+s.Addr(), []string{"vela"}, WithKeepalive(0))
+		if err != nil {
+			t.Fatalf("New returned err: %v", err)
+		}
+
+		// capture the idle connections pinged on every tick
+		idle := int(c.Queue.PoolStats().IdleConns)
+		if idle == 0 {
+			t.Fatalf("no idle connections in the pool to ping")
+		}
+
+		want := test.ticks * idle
+
+		if test.closed {
+			want = 0
+
+			c.Close()
+		}
+
+		ticks := make(chan time.Time)
+		done := make(chan struct{})
+
+		go func() {
+			c.keepalive(ticks)
+			close(done)
+		}()
+
+		before := s.CommandCount()
+
+		// each tick is received once the pings for the previous tick are sent
+		for i := 0; i < test.ticks; i++ {
+			select {
+			case ticks <- time.Now():
+			case <-done:
+			}
+		}
+
+		close(ticks)
+		<-done
+
+		got := s.CommandCount() - before
+
+		if got != want {
+			t.Errorf("Keepalive for %d ticks sent %d pings, want %d", test.ticks, got, want)
+		}
+
+		c.Close()
+		s.Close()
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Length returns the number of items pending in
+// the specified channel in the queue.
+func (c *client) Length(ctx context.Context, channel string) (int64, error) {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return 0, fmt.Errorf("unable to get length of queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	// send request to capture the length of the channel
+	length, err := c.Queue.WithContext(ctx).LLen(channel).Result()
+	if err != nil {
+		return 0, fmt.Errorf("unable to get length of queue channel %s: %w", channel, err)
+	}
+
+	return length, nil
+}
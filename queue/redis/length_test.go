@@ -0,0 +1,56 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Length(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		items int
+		want  int64
+	}{
+		{items: 0, want: 0},
+		{items: 1, want: 1},
+		{items: 2, want: 3},
+	}
+
+	// run tests
+	for _, test := range tests {
+		for i := 0; i < test.items; i++ {
+			err = c.Push(context.Background(), "vela", []byte("foo"))
+			if err != nil {
+				t.Errorf("Push returned err: %v", err)
+			}
+		}
+
+		got, err := c.Length(context.Background(), "vela")
+		if err != nil {
+			t.Errorf("Length returned err: %v", err)
+		}
+
+		if got != test.want {
+			t.Errorf("Length is %v, want %v", got, test.want)
+		}
+	}
+}
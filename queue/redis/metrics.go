@@ -0,0 +1,40 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Registry is the Prometheus registry for the metrics
+// captured while pulling items from the queue.
+//
+// The registry can be served with promhttp.HandlerFor.
+var Registry = prometheus.NewRegistry()
+
+// popWait captures the time spent blocked waiting for
+// an item from the queue labeled by the channel.
+var popWait = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "vela",
+		Subsystem: "worker",
+		Name:      "queue_pop_wait_seconds",
+		Help:      "Time spent blocked waiting for an item from the queue.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 14),
+	},
+	[]string{"channel"},
+)
+
+func init() {
+	Registry.MustRegister(popWait)
+}
+
+// observePop is a helper function to record the time
+// spent waiting for the item from the channel in the metrics.
+func observePop(channel string, start time.Time) {
+	popWait.WithLabelValues(channel).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Metrics_Pop(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"pop-metrics"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	wait := 200 * time.Millisecond
+
+	// push the item after the pop starts waiting
+	go func() {
+		time.Sleep(wait)
+
+		_, _ = s.Lpush("pop-metrics", `{"build":{"number":1}}`)
+	}()
+
+	// run test
+	_, _, err = c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Errorf("Gather returned err: %v", err)
+	}
+
+	var (
+		count uint64
+		sum   float64
+	)
+
+	for _, family := range families {
+		if family.GetName() != "vela_worker_queue_pop_wait_seconds" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "channel" && label.GetValue() == "pop-metrics" {
+					count += metric.GetHistogram().GetSampleCount()
+					sum += metric.GetHistogram().GetSampleSum()
+				}
+			}
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("queue_pop_wait_seconds observed %d values, want 1", count)
+	}
+
+	if sum < wait.Seconds() {
+		t.Errorf("queue_pop_wait_seconds observed %vs, want at least %vs", sum, wait.Seconds())
+	}
+}
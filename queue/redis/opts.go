@@ -0,0 +1,316 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ClientOpt represents a configuration option to initialize the queue client.
+type ClientOpt func(*client) error
+
+// WithPoolSize sets the maximum number of active connections
+// in the pool for the queue client. A value of 0 will use
+// the default from the Redis client.
+func WithPoolSize(size int) ClientOpt {
+	logrus.Trace("configuring pool size in queue client")
+
+	return func(c *client) error {
+		// check if the pool size provided is valid
+		if size < 0 {
+			return fmt.Errorf("invalid pool size provided to queue client: %d", size)
+		}
+
+		// set the pool size in the queue client
+		c.Options.PoolSize = size
+
+		return nil
+	}
+}
+
+// WithMinIdleConns sets the number of idle connections
+// kept open in the pool for the queue client.
+func WithMinIdleConns(conns int) ClientOpt {
+	logrus.Trace("configuring minimum idle connections in queue client")
+
+	return func(c *client) error {
+		// check if the idle connections provided is valid
+		if conns < 0 {
+			return fmt.Errorf("invalid minimum idle connections provided to queue client: %d", conns)
+		}
+
+		// set the minimum idle connections in the queue client
+		c.Options.MinIdleConns = conns
+
+		return nil
+	}
+}
+
+// WithIdleTimeout sets the amount of time an idle connection
+// remains in the pool for the queue client. A value of 0 will
+// use the default from the Redis client.
+func WithIdleTimeout(timeout time.Duration) ClientOpt {
+	logrus.Trace("configuring idle timeout in queue client")
+
+	return func(c *client) error {
+		// check if the idle timeout provided is valid
+		if timeout < 0 {
+			return fmt.Errorf("invalid idle timeout provided to queue client: %v", timeout)
+		}
+
+		// set the idle timeout in the queue client
+		c.Options.IdleTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithDB sets the database selected on each connection
+// for the queue client. A value of 0 will use the database
+// from the address provided to the queue client.
+func WithDB(db int) ClientOpt {
+	logrus.Trace("configuring database in queue client")
+
+	return func(c *client) error {
+		// check if the database provided is valid
+		if db < 0 {
+			return fmt.Errorf("invalid database provided to queue client: %d", db)
+		}
+
+		// set the database in the queue client
+		if db > 0 {
+			c.Options.DB = db
+		}
+
+		return nil
+	}
+}
+
+// WithAuth sets the credentials used to authenticate to the
+// queue for the queue client. The username is the ACL user
+// for Redis 6 and newer. An empty username or password will
+// use the credentials from the address provided to the queue
+// client, otherwise the default user is authenticated as.
+func WithAuth(username, password string) ClientOpt {
+	logrus.Trace("configuring auth in queue client")
+
+	return func(c *client) error {
+		// set the username in the queue client
+		if len(username) > 0 {
+			c.Username = username
+		}
+
+		// set the password in the queue client
+		if len(password) > 0 {
+			c.Options.Password = password
+		}
+
+		// check if a password is provided for the username
+		if len(c.Username) > 0 && len(c.Options.Password) == 0 {
+			return fmt.Errorf("no password provided for queue user %s", c.Username)
+		}
+
+		return nil
+	}
+}
+
+// WithPushTimeout sets the maximum amount of time to wait
+// when pushing an item to the queue. A value of 0 will
+// wait until the context provided is done.
+func WithPushTimeout(timeout time.Duration) ClientOpt {
+	logrus.Trace("configuring push timeout in queue client")
+
+	return func(c *client) error {
+		// check if the push timeout provided is valid
+		if timeout < 0 {
+			return fmt.Errorf("invalid push timeout provided to queue client: %v", timeout)
+		}
+
+		// set the push timeout in the queue client
+		c.PushTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithReconnectBackoff sets the amount of time waited before
+// the first attempt to reconnect to the queue and the maximum
+// amount of time waited between attempts. A backoff of 0 will
+// not attempt to reconnect to the queue.
+func WithReconnectBackoff(backoff, max time.Duration) ClientOpt {
+	logrus.Trace("configuring reconnect backoff in queue client")
+
+	return func(c *client) error {
+		// check if the reconnect backoff provided is valid
+		if backoff < 0 {
+			return fmt.Errorf("invalid reconnect backoff provided to queue client: %v", backoff)
+		}
+
+		// check if the maximum reconnect backoff provided is valid
+		if max < backoff {
+			return fmt.Errorf("invalid maximum reconnect backoff provided to queue client: %v", max)
+		}
+
+		// set the reconnect backoff in the queue client
+		c.ReconnectBackoff = backoff
+		c.ReconnectMaxBackoff = max
+
+		return nil
+	}
+}
+
+// WithKeepalive sets the amount of time waited between pings
+// of the idle connections in the pool for the queue client. A
+// value of 0 will not ping the idle connections.
+func WithKeepalive(interval time.Duration) ClientOpt {
+	logrus.Trace("configuring keepalive in queue client")
+
+	return func(c *client) error {
+		// check if the keepalive interval provided is valid
+		if interval < 0 {
+			return fmt.Errorf("invalid keepalive interval provided to queue client: %v", interval)
+		}
+
+		// set the keepalive interval in the queue client
+		c.KeepaliveInterval = interval
+
+		return nil
+	}
+}
+
+// WithStaleTimeout sets the amount of time an item stays in
+// processing without a heartbeat from the worker that popped it
+// before it is returned to its channel in the queue client. A
+// value of 0 will not return stale items.
+//
+// The timeout must be the same for every worker consuming
+// from the queue since the claims for the items popped are
+// only refreshed while the timeout is enabled.
+func WithStaleTimeout(timeout time.Duration) ClientOpt {
+	logrus.Trace("configuring stale timeout in queue client")
+
+	return func(c *client) error {
+		// check if the stale timeout provided is valid
+		if timeout < 0 {
+			return fmt.Errorf("invalid stale timeout provided to queue client: %v", timeout)
+		}
+
+		// set the stale timeout in the queue client
+		c.StaleTimeout = timeout
+
+		return nil
+	}
+}
+
+// WithPriorities sets the priorities for the channels in the
+// queue client in the form <channel>=<priority>. Channels with a
+// higher priority are drained first and channels without a
+// priority default to 0.
+func WithPriorities(priorities []string) ClientOpt {
+	logrus.Trace("configuring channel priorities in queue client")
+
+	return func(c *client) error {
+		c.Priorities = make(map[string]int)
+
+		// parse each of the provided priorities
+		for _, priority := range priorities {
+			parts := strings.SplitN(priority, "=", 2)
+			if len(parts) != 2 || len(parts[0]) == 0 {
+				return fmt.Errorf("invalid channel priority provided to queue client: %s", priority)
+			}
+
+			value, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return fmt.Errorf("invalid channel priority provided to queue client: %s", priority)
+			}
+
+			// set the channel priority in the queue client
+			c.Priorities[parts[0]] = value
+		}
+
+		return nil
+	}
+}
+
+// WithTLS enables TLS for the connection to the queue. The CA
+// certificate is used to verify the server and the client
+// certificate and key are used for mutual TLS. All paths are
+// optional and verification can be skipped for self-signed setups.
+func WithTLS(ca, cert, key string, skipVerify bool) ClientOpt {
+	logrus.Trace("configuring tls in queue client")
+
+	return func(c *client) error {
+		// create the TLS configuration from the provided files
+		config, err := tlsConfig(ca, cert, key, skipVerify)
+		if err != nil {
+			return err
+		}
+
+		// preserve the server name from a parsed rediss:// url
+		if c.Options.TLSConfig != nil {
+			config.ServerName = c.Options.TLSConfig.ServerName
+		}
+
+		// set the TLS configuration in the queue client
+		c.Options.TLSConfig = config
+
+		return nil
+	}
+}
+
+// tlsConfig is a helper function to create the
+// TLS configuration from the provided files.
+func tlsConfig(ca, cert, key string, skipVerify bool) (*tls.Config, error) {
+	// create the TLS configuration
+	//
+	// nolint:gosec // skipping verification is opt-in for self-signed setups
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: skipVerify,
+	}
+
+	// check if a CA certificate was provided
+	if len(ca) > 0 {
+		// read the CA certificate from the file
+		pem, err := ioutil.ReadFile(ca)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read queue CA certificate %s: %w", ca, err)
+		}
+
+		// create the certificate pool from the CA certificate
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("unable to parse queue CA certificate %s", ca)
+		}
+
+		config.RootCAs = pool
+	}
+
+	// check if only one of the client certificate or key was provided
+	if (len(cert) == 0) != (len(key) == 0) {
+		return nil, fmt.Errorf("both a client certificate and key must be provided for queue mutual TLS")
+	}
+
+	// check if a client certificate and key were provided
+	if len(cert) > 0 {
+		// load the client certificate and key from the files
+		pair, err := tls.LoadX509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load queue client certificate %s: %w", cert, err)
+		}
+
+		config.Certificates = []tls.Certificate{pair}
+	}
+
+	return config, nil
+}
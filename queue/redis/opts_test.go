@@ -0,0 +1,265 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+func TestRedis_ClientOpt_Pool(t *testing.T) {
+	// setup types
+	c := &client{Options: new(redis.Options)}
+
+	want := &redis.Options{
+		PoolSize:     25,
+		MinIdleConns: 5,
+		IdleTimeout:  10 * time.Minute,
+	}
+
+	opts := []ClientOpt{
+		WithPoolSize(25),
+		WithMinIdleConns(5),
+		WithIdleTimeout(10 * time.Minute),
+	}
+
+	// run test
+	for _, opt := range opts {
+		err := opt(c)
+		if err != nil {
+			t.Errorf("ClientOpt returned err: %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(c.Options, want) {
+		t.Errorf("Options is %v, want %v", c.Options, want)
+	}
+}
+
+func TestRedis_ClientOpt_Pool_Invalid(t *testing.T) {
+	// setup types
+	c := &client{Options: new(redis.Options)}
+
+	opts := []ClientOpt{
+		WithPoolSize(-1),
+		WithMinIdleConns(-1),
+		WithIdleTimeout(-1 * time.Minute),
+		WithPushTimeout(-1 * time.Second),
+		WithReconnectBackoff(-1*time.Second, time.Second),
+		WithReconnectBackoff(time.Second, time.Millisecond),
+		WithKeepalive(-1 * time.Second),
+		WithStaleTimeout(-1 * time.Second),
+		WithDB(-1),
+		WithAuth("vela", ""),
+	}
+
+	// run test
+	for _, opt := range opts {
+		err := opt(c)
+		if err == nil {
+			t.Errorf("ClientOpt should have returned err")
+		}
+	}
+}
+
+func TestRedis_ClientOpt_WithPriorities(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		priorities []string
+		want       map[string]int
+		failure    bool
+	}{
+		{priorities: []string{"linux=10", "vela=-1"}, want: map[string]int{"linux": 10, "vela": -1}},
+		{priorities: []string{}, want: map[string]int{}},
+		{priorities: []string{"linux"}, failure: true},
+		{priorities: []string{"=10"}, failure: true},
+		{priorities: []string{"linux=high"}, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c := &client{Options: new(redis.Options)}
+
+		err := WithPriorities(test.priorities)(c)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithPriorities for %v should have returned err", test.priorities)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithPriorities for %v returned err: %v", test.priorities, err)
+		}
+
+		if !reflect.DeepEqual(c.Priorities, test.want) {
+			t.Errorf("Priorities is %v, want %v", c.Priorities, test.want)
+		}
+	}
+}
+
+func TestRedis_ClientOpt_TLS(t *testing.T) {
+	// setup types
+	dir, err := ioutil.TempDir("", "redis")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	cert, key := writeCertificate(t, dir)
+
+	c := &client{Options: &redis.Options{TLSConfig: &tls.Config{ServerName: "redis.example.com"}}}
+
+	// run test
+	err = WithTLS(cert, cert, key, true)(c)
+	if err != nil {
+		t.Errorf("WithTLS returned err: %v", err)
+	}
+
+	got := c.Options.TLSConfig
+
+	if got.RootCAs == nil {
+		t.Errorf("TLSConfig RootCAs is nil")
+	}
+
+	if len(got.Certificates) != 1 {
+		t.Errorf("TLSConfig Certificates is %d, want 1", len(got.Certificates))
+	}
+
+	if !got.InsecureSkipVerify {
+		t.Errorf("TLSConfig InsecureSkipVerify is false, want true")
+	}
+
+	if got.ServerName != "redis.example.com" {
+		t.Errorf("TLSConfig ServerName is %s, want redis.example.com", got.ServerName)
+	}
+
+	if got.MinVersion != tls.VersionTLS12 {
+		t.Errorf("TLSConfig MinVersion is %d, want %d", got.MinVersion, tls.VersionTLS12)
+	}
+}
+
+func TestRedis_ClientOpt_TLS_Empty(t *testing.T) {
+	// setup types
+	c := &client{Options: new(redis.Options)}
+
+	// run test
+	err := WithTLS("", "", "", false)(c)
+	if err != nil {
+		t.Errorf("WithTLS returned err: %v", err)
+	}
+
+	got := c.Options.TLSConfig
+
+	if got == nil {
+		t.Fatalf("TLSConfig is nil")
+	}
+
+	if got.RootCAs != nil || len(got.Certificates) > 0 || got.InsecureSkipVerify {
+		t.Errorf("TLSConfig is %v, want default", got)
+	}
+}
+
+func TestRedis_ClientOpt_TLS_Invalid(t *testing.T) {
+	// setup types
+	dir, err := ioutil.TempDir("", "redis")
+	if err != nil {
+		t.Fatalf("unable to create temp dir: %v", err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	cert, key := writeCertificate(t, dir)
+
+	invalid := filepath.Join(dir, "invalid.pem")
+
+	err = ioutil.WriteFile(invalid, []byte("foo"), 0600)
+	if err != nil {
+		t.Fatalf("unable to write file: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		ca   string
+		cert string
+		key  string
+	}{
+		{ca: filepath.Join(dir, "missing.pem")},
+		{ca: invalid},
+		{cert: cert},
+		{key: key},
+		{cert: invalid, key: key},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c := &client{Options: new(redis.Options)}
+
+		err := WithTLS(test.ca, test.cert, test.key, false)(c)
+		if err == nil {
+			t.Errorf("WithTLS should have returned err")
+		}
+	}
+}
+
+// writeCertificate is a helper function to create a self-signed
+// certificate and key in the directory for testing.
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("unable to generate key: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis.example.com"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	if err != nil {
+		t.Fatalf("unable to create certificate: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(priv)
+	if err != nil {
+		t.Fatalf("unable to marshal key: %v", err)
+	}
+
+	cert := filepath.Join(dir, "cert.pem")
+	key := filepath.Join(dir, "key.pem")
+
+	err = ioutil.WriteFile(cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	if err != nil {
+		t.Fatalf("unable to write certificate: %v", err)
+	}
+
+	err = ioutil.WriteFile(key, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		t.Fatalf("unable to write key: %v", err)
+	}
+
+	return cert, key
+}
@@ -0,0 +1,29 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-vela/worker/queue"
+)
+
+// Ping sends a "ping" request to verify
+// the Redis queue instance is healthy.
+func (c *client) Ping(ctx context.Context) error {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return fmt.Errorf("unable to ping Redis queue: %w", queue.ErrClosed)
+	}
+
+	// send ping request to the queue
+	err := c.Queue.WithContext(ctx).Ping().Err()
+	if err != nil {
+		return fmt.Errorf("unable to ping Redis queue: %w", err)
+	}
+
+	return nil
+}
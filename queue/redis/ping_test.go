@@ -0,0 +1,41 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Ping(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// run test
+	err = c.Ping(context.Background())
+	if err != nil {
+		t.Errorf("Ping returned err: %v", err)
+	}
+
+	// stop the queue instance
+	s.Close()
+
+	err = c.Ping(context.Background())
+	if err == nil {
+		t.Errorf("Ping should have returned err")
+	}
+}
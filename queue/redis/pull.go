@@ -5,26 +5,345 @@
 package redis
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+
+	"github.com/go-redis/redis"
+	"github.com/sirupsen/logrus"
 )
 
-// Pop grabs an item from the specified channel off the queue.
-func (c *client) Pop() (*types.Item, error) {
-	// blocking list pop item from queue
-	result, err := c.Queue.BLPop(0, c.Channels...).Result()
-	if err != nil {
-		return nil, fmt.Errorf("unable to pop item from queue: %w", err)
+// popTimeout is the maximum amount of time a blocking pop
+// waits for an item before the context and the other
+// channels are checked again.
+const popTimeout = time.Second
+
+// claimFunction defines the Lua function shared by the scripts
+// that claim an item removed from the head of the channel.
+//
+// The item is assigned a unique id when it is first claimed, so
+// the claim and the expiry for the item are keyed by the id
+// rather than the item itself and duplicate items do not collide.
+//
+// Items pushed with a TTL that expired are dropped rather than
+// moved to the processing list for the channel.
+//
+// The time the item was claimed is recorded so the item
+// is returned to the channel if the worker stops.
+const claimFunction = `
+local function claim(item, now)
+  local id = string.match(item, "^(%d+):")
+  local expires = false
+  if id then
+    expires = redis.call("ZSCORE", KEYS[3], id)
+    if expires then
+      redis.call("ZREM", KEYS[3], id)
+    end
+  else
+    id = tostring(redis.call("INCR", KEYS[5]))
+    item = id .. ":" .. item
+  end
+  if expires and tonumber(expires) <= tonumber(now) then
+    return false
+  end
+  redis.call("RPUSH", KEYS[2], item)
+  redis.call("ZADD", KEYS[4], now, id)
+  return item
+end
+`
+
+// popScript atomically removes the first item from the channel
+// and appends it to the processing list for the channel. This
+// is the equivalent of RPOPLPUSH from the head of the channel,
+// which keeps items in the order they were pushed.
+//
+// Expired items are dropped and the next
+// item from the channel is checked.
+var popScript = redis.NewScript(claimFunction + `
+while true do
+  local item = redis.call("LPOP", KEYS[1])
+  if not item then
+    return item
+  end
+  local claimed = claim(item, ARGV[1])
+  if claimed then
+    return claimed
+  end
+end
+`)
+
+// claimScript appends the item removed from the head of the
+// channel by a blocking pop to the processing list for the
+// channel and records the time it was claimed. If the item
+// was pushed with a TTL that expired, it is dropped instead.
+var claimScript = redis.NewScript(claimFunction + `
+return claim(ARGV[2], ARGV[1])
+`)
+
+// after waits for the reconnect backoff to elapse.
+var after = time.After
+
+// Pop grabs an item from the specified channels off the queue
+// and returns it with the channel it was grabbed from.
+//
+// The channels are checked in order of their configured priority,
+// then in the order they are provided, so higher priority channels
+// are drained first. If no channels are provided, the channels
+// configured for the client are used.
+//
+// The pop blocks until an item is available or the
+// context provided is done, returning the context
+// error so the caller is able to shut down cleanly.
+// While no items are available, the pop blocks on the
+// highest priority channel and checks every channel
+// again after the pop timeout.
+//
+// The item is moved to the processing list for its channel
+// until it is acknowledged with Ack or returned with Nack,
+// so it is not lost if the worker stops before processing it.
+//
+// If the connection to the queue is lost, the pop
+// reconnects with backoff until the connection is
+// restored, the context provided is done or the
+// client is closed.
+func (c *client) Pop(ctx context.Context, channels ...string) (*types.Item, string, error) {
+	// check if any channels are provided
+	if len(channels) == 0 {
+		channels = c.Channels
+	}
+
+	// check if any channels are configured
+	if len(channels) == 0 {
+		return nil, "", fmt.Errorf("unable to pop item from queue: no channels provided")
+	}
+
+	// order the channels by their priority
+	channels = c.prioritize(channels)
+
+	// capture the time the pop started waiting
+	start := time.Now()
+
+	var attempts int
+
+	backoff := c.ReconnectBackoff
+
+	for {
+		// check if the context is done before blocking
+		select {
+		case <-ctx.Done():
+			return nil, "", fmt.Errorf("unable to pop item from queue: %w", ctx.Err())
+		default:
+		}
+
+		// check if the client for the queue was closed
+		if c.isClosed() {
+			return nil, "", fmt.Errorf("unable to pop item from queue: %w", queue.ErrClosed)
+		}
+
+		// move the next item from the queue to processing
+		channel, entry, err := c.next(channels)
+		if err == redis.Nil {
+			// wait for an item on the highest priority channel
+			channel, entry, err = c.block(channels[0])
+		}
+
+		if err != nil && err != redis.Nil {
+			// check if the client was closed while popping
+			if c.isClosed() {
+				return nil, "", fmt.Errorf("unable to pop item from queue: %w", queue.ErrClosed)
+			}
+
+			// check if reconnecting to the queue is disabled
+			if c.ReconnectBackoff == 0 {
+				return nil, "", fmt.Errorf("unable to pop item from queue: %w", err)
+			}
+
+			logrus.Warnf("unable to pop item from queue: %v. Reconnecting in %v", err, backoff)
+
+			// wait for the backoff unless the context is done
+			select {
+			case <-ctx.Done():
+				return nil, "", fmt.Errorf("unable to pop item from queue: %w", ctx.Err())
+			case <-after(backoff):
+			}
+
+			attempts++
+
+			// increase the backoff for the next attempt
+			backoff = nextBackoff(backoff, c.ReconnectMaxBackoff)
+
+			continue
+		}
+
+		// check if the connection to the queue was restored
+		if attempts > 0 {
+			logrus.Infof("reconnected to queue after %d attempts", attempts)
+
+			attempts = 0
+			backoff = c.ReconnectBackoff
+		}
+
+		// check if no item was available before the pop timeout
+		if err == redis.Nil {
+			continue
+		}
+
+		// unmarshal the entry into a queue item
+		item, err := c.item(channel, entry)
+		if err != nil {
+			return nil, "", err
+		}
+
+		// record the time spent waiting for the item
+		observePop(channel, start)
+
+		return item, channel, nil
 	}
+}
+
+// item is a helper function to unmarshal the entry moved to
+// processing into a queue item and track it until it is
+// acknowledged. If the entry is invalid, it is removed
+// from processing.
+func (c *client) item(channel, entry string) (*types.Item, error) {
+	id, data := split(entry)
 
 	item := new(types.Item)
 	// unmarshal result into queue item
-	err = json.Unmarshal([]byte(result[1]), item)
+	err := json.Unmarshal([]byte(data), item)
 	if err != nil {
+		// remove the invalid item from processing
+		rerr := c.release(channel, entry, id)
+		if rerr != nil {
+			logrus.Warnf("unable to remove invalid item from processing for queue channel %s: %v", channel, rerr)
+		}
+
 		return nil, fmt.Errorf("unable to unmarshal item from queue: %w", err)
 	}
 
+	// track the item until it is acknowledged
+	c.pending.Store(item, &pending{channel: channel, entry: entry, id: id})
+
 	return item, nil
 }
+
+// next is a helper function to move the first item from
+// the channels to processing and return it with its channel.
+// If no item is available, redis.Nil is returned.
+func (c *client) next(channels []string) (string, string, error) {
+	for _, channel := range channels {
+		// send script to move the item to processing
+		entry, err := popScript.Run(
+			c.Queue,
+			keys(channel),
+			milliseconds(time.Now()),
+		).String()
+		if err == redis.Nil {
+			continue
+		}
+
+		if err != nil {
+			return "", "", err
+		}
+
+		return channel, entry, nil
+	}
+
+	return "", "", redis.Nil
+}
+
+// block is a helper function to wait for an item on the channel
+// and move it to processing when it is pushed. If no item is
+// pushed before the pop timeout, redis.Nil is returned.
+//
+// The item is removed from the head of the channel, so items
+// are returned in the order they were pushed, and claimed right
+// after. An item is lost if the worker stops in between.
+func (c *client) block(channel string) (string, string, error) {
+	// send API call to wait for the item at the head of the channel
+	result, err := c.Queue.BLPop(popTimeout, channel).Result()
+	if err != nil {
+		return "", "", err
+	}
+
+	// send script to move the item to processing and record the claim
+	entry, err := claimScript.Run(
+		c.Queue,
+		keys(channel),
+		milliseconds(time.Now()),
+		result[1],
+	).String()
+	if err != nil {
+		return "", "", err
+	}
+
+	return channel, entry, nil
+}
+
+// keys is a helper function to create the
+// keys used to claim an item from the channel.
+func keys(channel string) []string {
+	return []string{channel, processing(channel), expiring(channel), claims(channel), ids(channel)}
+}
+
+// split is a helper function to separate the unique id assigned
+// to the entry for an item in the queue from the item itself.
+//
+// An entry without an id is keyed by the entry itself.
+func split(entry string) (string, string) {
+	i := strings.Index(entry, ":")
+
+	// check if the entry is prefixed with an id
+	if i <= 0 || strings.Trim(entry[:i], "0123456789") != "" {
+		return entry, entry
+	}
+
+	return entry[:i], entry[i+1:]
+}
+
+// ids is a helper function to create the name of the
+// counter used to assign a unique id to each item.
+func ids(channel string) string {
+	return fmt.Sprintf("%s:ids", channel)
+}
+
+// prioritize is a helper function to order the channels
+// by their configured priority from highest to lowest.
+//
+// Channels with the same priority keep the order
+// they were provided in.
+func (c *client) prioritize(channels []string) []string {
+	// check if any priorities are configured
+	if len(c.Priorities) == 0 {
+		return channels
+	}
+
+	// copy the channels to avoid reordering the provided slice
+	ordered := make([]string, len(channels))
+	copy(ordered, channels)
+
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return c.Priorities[ordered[i]] > c.Priorities[ordered[j]]
+	})
+
+	return ordered
+}
+
+// nextBackoff is a helper function to double
+// the backoff without exceeding the maximum.
+func nextBackoff(backoff, max time.Duration) time.Duration {
+	backoff *= 2
+
+	// check if the backoff exceeds the maximum
+	if backoff > max {
+		return max
+	}
+
+	return backoff
+}
@@ -0,0 +1,440 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+)
+
+func TestRedis_Pop_Success(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	want := 1
+
+	// run test
+	got, channel, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != want {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), want)
+	}
+
+	if channel != "vela" {
+		t.Errorf("Pop channel is %v, want %v", channel, "vela")
+	}
+}
+
+func TestRedis_Pop_Channels(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	_, err = s.Lpush("linux", `{"build":{"number":2}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		channels []string
+		want     int
+		channel  string
+	}{
+		{channels: []string{"linux", "vela"}, want: 2, channel: "linux"},
+		{channels: []string{"linux", "vela"}, want: 1, channel: "vela"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, channel, err := c.Pop(context.Background(), test.channels...)
+		if err != nil {
+			t.Errorf("Pop returned err: %v", err)
+		}
+
+		if got.Build.GetNumber() != test.want {
+			t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), test.want)
+		}
+
+		if channel != test.channel {
+			t.Errorf("Pop channel is %v, want %v", channel, test.channel)
+		}
+	}
+}
+
+func TestRedis_Pop_Priority(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela", "linux"}, WithPriorities([]string{"linux=10"}))
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	// push the high priority item after the low priority item
+	_, err = s.Lpush("linux", `{"build":{"number":2}}`)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	// run test
+	got, channel, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 2 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 2)
+	}
+
+	if channel != "linux" {
+		t.Errorf("Pop channel is %v, want %v", channel, "linux")
+	}
+
+	if c.Channels[0] != "vela" {
+		t.Errorf("Channels is %v, want the configured order", c.Channels)
+	}
+}
+
+func TestRedis_Pop_Reconnect(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"}, WithReconnectBackoff(10*time.Millisecond, 30*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// drop the connection to the queue
+	s.Close()
+
+	var got []time.Duration
+
+	want := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		30 * time.Millisecond,
+	}
+
+	// capture the backoff and restore the connection after the last attempt
+	after = func(d time.Duration) <-chan time.Time {
+		got = append(got, d)
+
+		if len(got) == len(want) {
+			err := s.Restart()
+			if err != nil {
+				t.Fatalf("unable to restart miniredis: %v", err)
+			}
+
+			_, err = s.Lpush("vela", `{"build":{"number":1}}`)
+			if err != nil {
+				t.Fatalf("unable to push item: %v", err)
+			}
+		}
+
+		ch := make(chan time.Time, 1)
+		ch <- time.Now()
+
+		return ch
+	}
+	defer func() { after = time.After }()
+
+	// run test
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if item.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want %v", item.Build.GetNumber(), 1)
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Pop backoff is %v, want %v", got, want)
+	}
+}
+
+func TestRedis_Pop_Canceled(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// cancel the context while waiting on an empty queue
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+	}()
+
+	// run test
+	got, _, err := c.Pop(ctx)
+	if err == nil {
+		t.Errorf("Pop should have returned err")
+	}
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Pop returned err %v, want %v", err, context.Canceled)
+	}
+
+	if got != nil {
+		t.Errorf("Pop is %v, want nil", got)
+	}
+}
+
+func TestRedis_Pop_Blocking(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// setup tests
+	tests := []struct {
+		expires float64
+		want    int
+	}{
+		{want: 1},
+		{expires: 1, want: 2},
+	}
+
+	// run tests
+	for _, test := range tests {
+		// push the items while the pop is blocked on an empty queue
+		go func(expires float64, want int) {
+			time.Sleep(50 * time.Millisecond)
+
+			// push an item with a TTL that already expired
+			if expires > 0 {
+				c.Queue.ZAdd(expiring("vela"), redis.Z{Score: expires, Member: "1"})
+				c.Queue.RPush("vela", `1:{"build":{"number":1}}`)
+
+				time.Sleep(50 * time.Millisecond)
+			}
+
+			c.Queue.RPush("vela", fmt.Sprintf(`{"build":{"number":%d}}`, want))
+		}(test.expires, test.want)
+
+		got, _, err := c.Pop(context.Background())
+		if err != nil {
+			t.Errorf("Pop returned err: %v", err)
+		}
+
+		if got.Build.GetNumber() != test.want {
+			t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), test.want)
+		}
+
+		data := fmt.Sprintf(`{"build":{"number":%d}}`, test.want)
+
+		processing, _ := s.List("vela:processing")
+		if len(processing) != 1 {
+			t.Fatalf("Pop processing is %v, want 1 item", processing)
+		}
+
+		id, entry := split(processing[0])
+		if entry != data {
+			t.Errorf("Pop processing is %v, want %v", entry, data)
+		}
+
+		// the claim for the item is recorded
+		_, err = s.ZScore("vela:claims", id)
+		if err != nil {
+			t.Errorf("Pop should have recorded the claim for the item: %v", err)
+		}
+
+		err = c.Ack(context.Background(), got)
+		if err != nil {
+			t.Errorf("Ack returned err: %v", err)
+		}
+	}
+}
+
+func TestRedis_Pop_BlockingOrder(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// push the items at once while the pop is blocked on an empty queue
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		c.Queue.RPush("vela", `{"build":{"number":1}}`, `{"build":{"number":2}}`)
+	}()
+
+	// run test
+	for _, want := range []int{1, 2} {
+		got, _, err := c.Pop(context.Background())
+		if err != nil {
+			t.Errorf("Pop returned err: %v", err)
+
+			continue
+		}
+
+		if got.Build.GetNumber() != want {
+			t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), want)
+		}
+	}
+}
+
+func TestRedis_Pop_Duplicate(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	data := `{"build":{"number":1}}`
+
+	// push the same item twice
+	for i := 0; i < 2; i++ {
+		_, err = s.Push("vela", data)
+		if err != nil {
+			t.Fatalf("unable to push item: %v", err)
+		}
+	}
+
+	first, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	_, _, err = c.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	err = c.Ack(context.Background(), first)
+	if err != nil {
+		t.Errorf("Ack returned err: %v", err)
+	}
+
+	processing, _ := s.List("vela:processing")
+	if len(processing) != 1 {
+		t.Fatalf("Ack processing is %v, want 1 item", processing)
+	}
+
+	// the claim for the duplicate item is kept
+	id, _ := split(processing[0])
+
+	_, err = s.ZScore("vela:claims", id)
+	if err != nil {
+		t.Errorf("Ack should not have removed the claim for the duplicate item: %v", err)
+	}
+}
+
+func TestRedis_split(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		entry string
+		id    string
+		data  string
+	}{
+		{entry: `12:{"build":{}}`, id: "12", data: `{"build":{}}`},
+		{entry: `{"build":{}}`, id: `{"build":{}}`, data: `{"build":{}}`},
+		{entry: `:{"build":{}}`, id: `:{"build":{}}`, data: `:{"build":{}}`},
+		{entry: "foo:bar", id: "foo:bar", data: "foo:bar"},
+	}
+
+	// run tests
+	for _, test := range tests {
+		id, data := split(test.entry)
+
+		if id != test.id {
+			t.Errorf("split id is %v, want %v", id, test.id)
+		}
+
+		if data != test.data {
+			t.Errorf("split data is %v, want %v", data, test.data)
+		}
+	}
+}
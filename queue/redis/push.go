@@ -0,0 +1,109 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-vela/worker/queue"
+
+	"github.com/go-redis/redis"
+)
+
+// pushScript assigns a unique id to the item and pushes it to the
+// end of the channel with the id, recording the expiry for the item
+// by its id so duplicate items pushed with a TTL do not collide.
+var pushScript = redis.NewScript(`
+local id = tostring(redis.call("INCR", KEYS[3]))
+redis.call("ZADD", KEYS[2], ARGV[1], id)
+redis.call("RPUSH", KEYS[1], id .. ":" .. ARGV[2])
+return id
+`)
+
+// Push inserts an item to the specified channel in the queue.
+//
+// The push is bounded by the context provided and the
+// configured push timeout so the caller is not blocked
+// when the queue is slow or unreachable.
+func (c *client) Push(ctx context.Context, channel string, item []byte) error {
+	return c.push(ctx, channel, func() error {
+		// push item to the end of the queue
+		return c.Queue.RPush(channel, item).Err()
+	})
+}
+
+// PushTTL inserts an item to the specified channel in the
+// queue that expires after the provided TTL.
+//
+// An item that expired before it is popped is dropped
+// from the queue rather than returned by Pop.
+func (c *client) PushTTL(ctx context.Context, channel string, item []byte, ttl time.Duration) error {
+	// check if the TTL provided is valid
+	if ttl <= 0 {
+		return fmt.Errorf("invalid TTL provided for item in queue channel %s: %v", channel, ttl)
+	}
+
+	// capture the time the item expires
+	expires := milliseconds(time.Now().Add(ttl))
+
+	return c.push(ctx, channel, func() error {
+		// send script to push the item with its expiry
+		return pushScript.Run(
+			c.Queue,
+			[]string{channel, expiring(channel), ids(channel)},
+			expires,
+			item,
+		).Err()
+	})
+}
+
+// push is a helper function to run the push to the specified
+// channel bounded by the context and configured push timeout.
+func (c *client) push(ctx context.Context, channel string, fn func() error) error {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return fmt.Errorf("unable to push item to queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	// check if a timeout is configured for pushing items
+	if c.PushTimeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, c.PushTimeout)
+		defer cancel()
+	}
+
+	// create a channel to capture the result of the push
+	result := make(chan error, 1)
+
+	go func() {
+		result <- fn()
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("unable to push item to queue channel %s: %w", channel, ctx.Err())
+	case err := <-result:
+		if err != nil {
+			return fmt.Errorf("unable to push item to queue channel %s: %w", channel, err)
+		}
+
+		return nil
+	}
+}
+
+// expiring is a helper function to create the name of the
+// sorted set holding the expiry for items in the channel.
+func expiring(channel string) string {
+	return fmt.Sprintf("%s:expires", channel)
+}
+
+// milliseconds is a helper function to convert
+// the time to milliseconds since the Unix epoch.
+func milliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
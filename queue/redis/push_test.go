@@ -0,0 +1,165 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+)
+
+func TestRedis_Push_Timeout(t *testing.T) {
+	// setup types
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unable to create listener: %v", err)
+	}
+
+	defer l.Close()
+
+	// accept connections but never respond to simulate a blocked queue
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			defer conn.Close()
+		}
+	}()
+
+	c := &client{
+		Queue: redis.NewClient(&redis.Options{
+			Addr:        l.Addr().String(),
+			ReadTimeout: time.Minute,
+		}),
+		PushTimeout: 50 * time.Millisecond,
+	}
+
+	defer c.Queue.Close()
+
+	// run test
+	start := time.Now()
+
+	err = c.Push(context.Background(), "vela", []byte("foo"))
+	if err == nil {
+		t.Errorf("Push should have returned err")
+	}
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Push returned err %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("Push took %v, want timeout to fire", time.Since(start))
+	}
+}
+
+func TestRedis_PushTTL(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	defer c.Queue.Close()
+
+	// run test
+	err = c.PushTTL(context.Background(), "vela", []byte(`{"build":{"number":1}}`), 50*time.Millisecond)
+	if err != nil {
+		t.Errorf("PushTTL returned err: %v", err)
+	}
+
+	err = c.PushTTL(context.Background(), "vela", []byte(`{"build":{"number":2}}`), time.Hour)
+	if err != nil {
+		t.Errorf("PushTTL returned err: %v", err)
+	}
+
+	// wait for the first item to expire
+	time.Sleep(100 * time.Millisecond)
+
+	item, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if item.Build.GetNumber() != 2 {
+		t.Errorf("Pop returned build %d, want 2", item.Build.GetNumber())
+	}
+
+	length, _ := c.Length(context.Background(), "vela")
+	if length != 0 {
+		t.Errorf("Length is %v, want 0", length)
+	}
+
+	if s.Exists("vela:expires") {
+		t.Errorf("Pop should have removed the expiry for the items")
+	}
+}
+
+func TestRedis_PushTTL_Invalid(t *testing.T) {
+	// setup types
+	c := new(client)
+
+	// run test
+	err := c.PushTTL(context.Background(), "vela", []byte("foo"), 0)
+	if err == nil {
+		t.Errorf("PushTTL should have returned err")
+	}
+}
+
+func TestRedis_PushTTL_Duplicate(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	defer c.Queue.Close()
+
+	data := []byte(`{"build":{"number":1}}`)
+
+	// push the same item with a different TTL
+	for _, ttl := range []time.Duration{50 * time.Millisecond, time.Hour} {
+		err = c.PushTTL(context.Background(), "vela", data, ttl)
+		if err != nil {
+			t.Errorf("PushTTL returned err: %v", err)
+		}
+	}
+
+	// wait for the first item to expire
+	time.Sleep(100 * time.Millisecond)
+
+	// run test
+	_, _, err = c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	length, _ := c.Length(context.Background(), "vela")
+	if length != 0 {
+		t.Errorf("Length is %v, want 0", length)
+	}
+}
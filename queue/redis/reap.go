@@ -0,0 +1,157 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultStaleTimeout defines the default amount of time an
+// item stays in processing without a heartbeat from the
+// worker that popped it before it is returned to the channel.
+const defaultStaleTimeout = 5 * time.Minute
+
+// reapScript returns the item to the head of the channel if the
+// claim for the item in processing is older than the cutoff.
+//
+// An item in processing without a claim, left by a worker that
+// stopped before recording it, is claimed at the current time
+// so it is returned once the claim is stale.
+var reapScript = redis.NewScript(`
+local claimed = redis.call("ZSCORE", KEYS[2], ARGV[4])
+if not claimed then
+  redis.call("ZADD", KEYS[2], ARGV[1], ARGV[4])
+  return 0
+end
+if tonumber(claimed) > tonumber(ARGV[2]) then
+  return 0
+end
+redis.call("ZREM", KEYS[2], ARGV[4])
+if redis.call("LREM", KEYS[1], 1, ARGV[3]) == 0 then
+  return 0
+end
+redis.call("LPUSH", KEYS[3], ARGV[3])
+return 1
+`)
+
+// startReaper is a helper function to start returning the
+// stale items in processing to their channels if a stale
+// timeout was provided.
+func (c *client) startReaper() {
+	// check if the reaper is disabled
+	if c.StaleTimeout <= 0 {
+		return
+	}
+
+	go c.reaper(c.StaleTimeout / 3)
+}
+
+// reaper is a helper function to refresh the claims for the
+// items popped by the client and return the stale items in
+// processing to their channels on the interval.
+//
+// The reaper stops once the queue client is closed.
+func (c *client) reaper(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		// check if the queue client was closed
+		if c.isClosed() {
+			return
+		}
+
+		now := time.Now()
+
+		// refresh the claims for the items popped by the client
+		c.heartbeat(now)
+
+		// return the stale items to their channels
+		c.reap(now)
+	}
+}
+
+// heartbeat is a helper function to refresh the claims
+// for the items popped by the client that have not
+// been acknowledged yet.
+func (c *client) heartbeat(now time.Time) {
+	c.pending.Range(func(_, value interface{}) bool {
+		p := value.(*pending)
+
+		// send API call to refresh the claim for the item
+		err := c.Queue.ZAddXX(claims(p.channel), redis.Z{
+			Score:  float64(milliseconds(now)),
+			Member: p.id,
+		}).Err()
+		if err != nil {
+			logrus.Warnf("unable to refresh claim for item in queue channel %s: %v", p.channel, err)
+		}
+
+		return true
+	})
+}
+
+// reap is a helper function to return the items in processing
+// for the channels configured for the client to the head of
+// their channel once their claim is older than the stale timeout.
+func (c *client) reap(now time.Time) {
+	cutoff := milliseconds(now.Add(-c.StaleTimeout))
+
+	for _, channel := range c.Channels {
+		// send API call to capture the items in processing
+		items, err := c.Queue.LRange(processing(channel), 0, -1).Result()
+		if err != nil {
+			logrus.Warnf("unable to list items in processing for queue channel %s: %v", channel, err)
+
+			continue
+		}
+
+		for _, entry := range items {
+			id, _ := split(entry)
+
+			// send script to return the item if it is stale
+			reaped, err := reapScript.Run(
+				c.Queue,
+				[]string{processing(channel), claims(channel), channel},
+				milliseconds(now),
+				cutoff,
+				entry,
+				id,
+			).Int()
+			if err != nil {
+				logrus.Warnf("unable to reap item in processing for queue channel %s: %v", channel, err)
+
+				continue
+			}
+
+			if reaped == 1 {
+				logrus.Infof("returned stale item in processing to queue channel %s", channel)
+			}
+		}
+	}
+}
+
+// release is a helper function to remove
+// the item and its claim from processing.
+func (c *client) release(channel, entry, id string) error {
+	_, err := c.Queue.TxPipelined(func(pipe redis.Pipeliner) error {
+		pipe.LRem(processing(channel), 1, entry)
+		pipe.ZRem(claims(channel), id)
+
+		return nil
+	})
+
+	return err
+}
+
+// claims is a helper function to create the name of the
+// sorted set holding the time each item was claimed.
+func claims(channel string) string {
+	return fmt.Sprintf("%s:claims", channel)
+}
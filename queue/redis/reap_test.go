@@ -0,0 +1,136 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_reap(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	// create the client for the worker that crashes
+	crashed, err := New("redis://"+s.Addr(), []string{"vela"}, WithStaleTimeout(0))
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	data := `{"build":{"number":1}}`
+
+	_, err = s.Push("vela", data)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	// pop the item on the worker that crashes before acknowledging it
+	_, _, err = crashed.Pop(context.Background())
+	if err != nil {
+		t.Fatalf("Pop returned err: %v", err)
+	}
+
+	// run test
+	now := time.Now()
+
+	c.reap(now)
+
+	if s.Exists("vela") {
+		t.Errorf("reap should not have returned the item with a fresh claim")
+	}
+
+	c.reap(now.Add(defaultStaleTimeout + time.Second))
+
+	items, _ := s.List("vela")
+	if len(items) != 1 {
+		t.Fatalf("reap channel is %v, want 1 item", items)
+	}
+
+	if _, got := split(items[0]); got != data {
+		t.Errorf("reap channel is %v, want %v", got, data)
+	}
+
+	if s.Exists("vela:processing") || s.Exists("vela:claims") {
+		t.Errorf("reap should have removed the item from processing")
+	}
+
+	// pop the item returned to the channel on another worker
+	got, _, err := c.Pop(context.Background())
+	if err != nil {
+		t.Errorf("Pop returned err: %v", err)
+	}
+
+	if got.Build.GetNumber() != 1 {
+		t.Errorf("Pop is %v, want %v", got.Build.GetNumber(), 1)
+	}
+
+	// refresh the claim for the item popped by the worker
+	c.heartbeat(now.Add(2 * defaultStaleTimeout))
+
+	c.reap(now.Add(2*defaultStaleTimeout + time.Second))
+
+	if s.Exists("vela") {
+		t.Errorf("reap should not have returned the item with a refreshed claim")
+	}
+}
+
+func TestRedis_reap_Unclaimed(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	data := `{"build":{"number":1}}`
+
+	// add an item to processing without a claim as if the
+	// worker crashed right after moving it to processing
+	_, err = s.Push("vela:processing", data)
+	if err != nil {
+		t.Fatalf("unable to push item: %v", err)
+	}
+
+	// run test
+	now := time.Now()
+
+	c.reap(now)
+
+	if s.Exists("vela") {
+		t.Errorf("reap should not have returned the unclaimed item")
+	}
+
+	_, err = s.ZScore("vela:claims", data)
+	if err != nil {
+		t.Errorf("reap should have claimed the unclaimed item: %v", err)
+	}
+
+	c.reap(now.Add(defaultStaleTimeout + time.Second))
+
+	items, _ := s.List("vela")
+	if !reflect.DeepEqual(items, []string{data}) {
+		t.Errorf("reap channel is %v, want %v", items, []string{data})
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/worker/queue"
+
+	"github.com/go-redis/redis"
+)
+
+// Remove removes the items pending in the specified channel
+// in the queue that match the predicate and returns the
+// number of items removed.
+//
+// Items that are unable to be unmarshaled are left in
+// the channel, and items popped while the channel is
+// scanned are not removed.
+func (c *client) Remove(ctx context.Context, channel string, match func(*types.Item) bool) (int64, error) {
+	// check if the client for the queue was closed
+	if c.isClosed() {
+		return 0, fmt.Errorf("unable to remove items from queue channel %s: %w", channel, queue.ErrClosed)
+	}
+
+	client := c.Queue.WithContext(ctx)
+
+	// send request to capture the items pending in the channel
+	items, err := client.LRange(channel, 0, -1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("unable to scan queue channel %s: %w", channel, err)
+	}
+
+	// capture the entries for the items matching the predicate
+	matches := []string{}
+
+	for _, entry := range items {
+		_, data := split(entry)
+
+		item := new(types.Item)
+
+		// unmarshal data into queue item
+		err = json.Unmarshal([]byte(data), item)
+		if err != nil {
+			continue
+		}
+
+		if match(item) {
+			matches = append(matches, entry)
+		}
+	}
+
+	// check if any items match the predicate
+	if len(matches) == 0 {
+		return 0, nil
+	}
+
+	var removes []*redis.IntCmd
+
+	_, err = client.TxPipelined(func(pipe redis.Pipeliner) error {
+		for _, entry := range matches {
+			id, _ := split(entry)
+
+			// remove the item from the channel
+			removes = append(removes, pipe.LRem(channel, 1, entry))
+
+			// remove the expiry recorded for the item
+			pipe.ZRem(expiring(channel), id)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to remove items from queue channel %s: %w", channel, err)
+	}
+
+	var removed int64
+
+	// count the items removed from the channel
+	for _, remove := range removes {
+		removed += remove.Val()
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/go-vela/types"
+	"github.com/go-vela/types/library"
+
+	"github.com/alicebob/miniredis"
+)
+
+func TestRedis_Remove(t *testing.T) {
+	// setup types
+	s, err := miniredis.Run()
+	if err != nil {
+		t.Fatalf("unable to create miniredis: %v", err)
+	}
+
+	defer s.Close()
+
+	c, err := New("redis://"+s.Addr(), []string{"vela"})
+	if err != nil {
+		t.Fatalf("New returned err: %v", err)
+	}
+
+	// push an item for each build to the queue
+	for _, id := range []int64{1, 2, 3, 2} {
+		id := id
+
+		bytes, err := json.Marshal(&types.Item{Build: &library.Build{ID: &id}})
+		if err != nil {
+			t.Fatalf("unable to marshal item: %v", err)
+		}
+
+		err = c.Push(context.Background(), "vela", bytes)
+		if err != nil {
+			t.Errorf("Push returned err: %v", err)
+		}
+	}
+
+	want := []int64{1, 3}
+
+	// run test
+	removed, err := c.Remove(context.Background(), "vela", func(item *types.Item) bool {
+		return item.Build.GetID() == 2
+	})
+	if err != nil {
+		t.Errorf("Remove returned err: %v", err)
+	}
+
+	if removed != 2 {
+		t.Errorf("Remove removed %d items, want 2", removed)
+	}
+
+	got := []int64{}
+
+	// pop the items left in the queue
+	for range want {
+		item, _, err := c.Pop(context.Background())
+		if err != nil {
+			t.Errorf("Pop returned err: %v", err)
+
+			break
+		}
+
+		got = append(got, item.Build.GetID())
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Remove left builds %v, want %v", got, want)
+	}
+
+	length, err := c.Length(context.Background(), "vela")
+	if err != nil {
+		t.Errorf("Length returned err: %v", err)
+	}
+
+	if length != 0 {
+		t.Errorf("Length is %d, want 0", length)
+	}
+}
@@ -0,0 +1,20 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Broadcaster is a middleware function that attaches the handler used for
+// streaming the step logs over a websocket to the context of every http.Request.
+func Broadcaster(b http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("broadcaster", b)
+		c.Next()
+	}
+}
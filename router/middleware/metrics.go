@@ -0,0 +1,30 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics is a middleware function that attaches the handler used for
+// serving the Prometheus metrics to the context of every http.Request.
+//
+// The handler serving the metrics from the gatherer is created once
+// when the middleware is set up rather than for every http.Request.
+func Metrics(g prometheus.Gatherer) gin.HandlerFunc {
+	// create the handler instrumented with the metrics for serving the metrics
+	handler := promhttp.InstrumentMetricHandler(
+		prometheus.DefaultRegisterer,
+		promhttp.HandlerFor(g, promhttp.HandlerOpts{}),
+	)
+
+	return func(c *gin.Context) {
+		c.Set("metrics", handler)
+		c.Next()
+	}
+}
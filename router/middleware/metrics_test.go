@@ -0,0 +1,64 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestMiddleware_Metrics(t *testing.T) {
+	// setup types
+	registry := prometheus.NewRegistry()
+
+	counter := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "vela_test_total",
+		Help: "Total number of tests.",
+	})
+	registry.MustRegister(counter)
+
+	var handlers []http.Handler
+
+	// setup context
+	gin.SetMode(gin.TestMode)
+
+	_, engine := gin.CreateTestContext(httptest.NewRecorder())
+
+	// setup mock server
+	engine.Use(Metrics(registry))
+	engine.GET("/metrics", func(c *gin.Context) {
+		handler := c.MustGet("metrics").(http.Handler)
+		handlers = append(handlers, handler)
+
+		handler.ServeHTTP(c.Writer, c.Request)
+	})
+
+	// run test
+	for i := 0; i < 2; i++ {
+		resp := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+
+		engine.ServeHTTP(resp, req)
+
+		if resp.Code != http.StatusOK {
+			t.Errorf("Metrics returned %v, want %v", resp.Code, http.StatusOK)
+		}
+
+		if !strings.Contains(resp.Body.String(), "vela_test_total") {
+			t.Errorf("Metrics body is %s, want vela_test_total", resp.Body.String())
+		}
+	}
+
+	if len(handlers) != 2 || reflect.ValueOf(handlers[0]).Pointer() != reflect.ValueOf(handlers[1]).Pointer() {
+		t.Errorf("Metrics should have attached the same handler to every request")
+	}
+}
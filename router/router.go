@@ -30,13 +30,14 @@ func Load(options ...gin.HandlerFunc) *gin.Engine {
 	r.Use(options...)
 
 	r.GET("/health", api.Health)
-	r.GET("/metrics", gin.WrapH(api.Metrics()))
+	r.GET("/metrics", api.Metrics)
 
 	// api endpoints
 	baseAPI := r.Group(base, user.Establish(), perm.MustServer())
 	{
 		// executor endpoints
 		executorHandlers(baseAPI)
+		baseAPI.GET("/logs", api.StreamLogs)
 		baseAPI.POST("/shutdown", api.Shutdown)
 	} // end of api
 
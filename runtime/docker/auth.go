@@ -0,0 +1,88 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRegistry defines the domain used
+// for images pulled from Docker Hub.
+const defaultRegistry = "docker.io"
+
+// WithRegistryAuth sets the credentials used for
+// pulling images from the registry in the Runtime.
+func (c *client) WithRegistryAuth(registry, username, password string) *client {
+	// set credentials in runtime if a valid registry and username are provided
+	if len(registry) == 0 || len(username) == 0 {
+		return c
+	}
+
+	// create the credentials map if it doesn't exist
+	if c.Credentials == nil {
+		c.Credentials = make(map[string]types.AuthConfig)
+	}
+
+	domain := registryDomain(registry)
+
+	c.Credentials[domain] = types.AuthConfig{
+		Username:      username,
+		Password:      password,
+		ServerAddress: domain,
+	}
+
+	return c
+}
+
+// registryAuth is a helper function to return the encoded
+// credentials for pulling the image from its registry.
+func (c *client) registryAuth(image string) (string, error) {
+	// parse the image reference
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return "", err
+	}
+
+	// check if credentials exist for the image registry
+	auth, ok := c.Credentials[reference.Domain(named)]
+	if !ok {
+		return "", nil
+	}
+
+	logrus.Tracef("Using credentials for registry %s", auth.ServerAddress)
+
+	// marshal the credentials
+	data, err := json.Marshal(auth)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.URLEncoding.EncodeToString(data), nil
+}
+
+// registryDomain is a helper function to
+// normalize the domain for the registry.
+func registryDomain(registry string) string {
+	// trim the scheme from the registry
+	domain := strings.TrimPrefix(registry, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+
+	// trim the path from the registry
+	domain = strings.Split(domain, "/")[0]
+
+	// check if the registry is Docker Hub
+	if domain == "index.docker.io" || domain == "registry-1.docker.io" {
+		return defaultRegistry
+	}
+
+	return domain
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/go-vela/worker/runtime/docker/testdata/mock"
+)
+
+func TestDocker_WithRegistryAuth(t *testing.T) {
+	// setup types
+	want := map[string]types.AuthConfig{
+		"docker.io": {
+			Username:      "octocat",
+			Password:      "superSecretPassword",
+			ServerAddress: "docker.io",
+		},
+		"registry.example.com": {
+			Username:      "octokitty",
+			Password:      "superSecretPassword",
+			ServerAddress: "registry.example.com",
+		},
+	}
+
+	// setup Docker
+	c, _ := NewMock()
+
+	// run test
+	c.WithRegistryAuth("https://index.docker.io/v1/", "octocat", "superSecretPassword")
+	c.WithRegistryAuth("registry.example.com", "octokitty", "superSecretPassword")
+	c.WithRegistryAuth("", "octocat", "superSecretPassword")
+	c.WithRegistryAuth("registry.example.org", "", "")
+
+	if !reflect.DeepEqual(c.Credentials, want) {
+		t.Errorf("Credentials is %v, want %v", c.Credentials, want)
+	}
+}
+
+func TestDocker_SetupContainer_RegistryAuth(t *testing.T) {
+	// setup types
+	var got string
+
+	// capture the credentials sent when pulling the image
+	doer := func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/images/create") {
+			got = r.Header.Get("X-Registry-Auth")
+		}
+
+		return mock.Router(r)
+	}
+
+	// setup Docker
+	r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+	c := &client{Runtime: r}
+	c.WithRegistryAuth("registry.example.com", "octocat", "superSecretPassword")
+
+	// setup tests
+	tests := []struct {
+		image string
+		want  *types.AuthConfig
+	}{
+		{
+			image: "registry.example.com/github/octocat:latest",
+			want: &types.AuthConfig{
+				Username:      "octocat",
+				Password:      "superSecretPassword",
+				ServerAddress: "registry.example.com",
+			},
+		},
+		{
+			image: "alpine:latest",
+			want:  nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got = ""
+
+		err := c.SetupContainer(context.Background(), &pipeline.Container{
+			ID:    "container_id",
+			Image: test.image,
+			Pull:  true,
+		})
+		if err != nil {
+			t.Errorf("SetupContainer for %s returned err: %v", test.image, err)
+		}
+
+		if test.want == nil {
+			if len(got) > 0 {
+				t.Errorf("SetupContainer for %s sent credentials %s, want none", test.image, got)
+			}
+
+			continue
+		}
+
+		data, err := base64.URLEncoding.DecodeString(got)
+		if err != nil {
+			t.Errorf("unable to decode credentials for %s: %v", test.image, err)
+		}
+
+		auth := new(types.AuthConfig)
+
+		err = json.Unmarshal(data, auth)
+		if err != nil {
+			t.Errorf("unable to unmarshal credentials for %s: %v", test.image, err)
+		}
+
+		if !reflect.DeepEqual(auth, test.want) {
+			t.Errorf("SetupContainer for %s sent credentials %v, want %v", test.image, auth, test.want)
+		}
+	}
+}
@@ -9,24 +9,31 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/go-vela/types/pipeline"
+	"github.com/go-vela/worker/runtime"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/mount"
 	docker "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/docker/go-units"
 
 	"github.com/sirupsen/logrus"
 )
 
+// maxSignal defines the highest signal number
+// a container can be terminated by.
+const maxSignal = 31
+
 // InspectContainer inspects the pipeline container.
 func (c *client) InspectContainer(ctx context.Context, ctn *pipeline.Container) error {
 	logrus.Tracef("Inspecting container for step %s", ctn.ID)
 
 	// send API call to inspect the container
-	container, err := c.Runtime.ContainerInspect(ctx, ctn.ID)
+	container, err := c.Runtime.ContainerInspect(ctx, c.containerName(ctn))
 	if err != nil {
 		return err
 	}
@@ -34,6 +41,18 @@ func (c *client) InspectContainer(ctx context.Context, ctn *pipeline.Container)
 	// set the exit code
 	ctn.ExitCode = container.State.ExitCode
 
+	// check if the container exceeded its memory limit
+	if container.State.OOMKilled {
+		return runtime.ErrOOMKilled
+	}
+
+	// check if the container was terminated by a signal
+	//
+	// https://tldp.org/LDP/abs/html/exitcodes.html
+	if ctn.ExitCode > 128 && ctn.ExitCode <= 128+maxSignal {
+		return fmt.Errorf("%w by signal %d", runtime.ErrKilled, ctn.ExitCode-128)
+	}
+
 	return nil
 }
 
@@ -42,7 +61,7 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 	logrus.Tracef("Removing container for step %s", ctn.ID)
 
 	// send API call to inspect the container
-	container, err := c.Runtime.ContainerInspect(ctx, ctn.ID)
+	container, err := c.Runtime.ContainerInspect(ctx, c.containerName(ctn))
 	if err != nil {
 		return err
 	}
@@ -52,7 +71,7 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 		container.State.Restarting ||
 		container.State.Running {
 		// send API call to kill the container
-		err := c.Runtime.ContainerKill(ctx, ctn.ID, "SIGKILL")
+		err := c.Runtime.ContainerKill(ctx, c.containerName(ctn), "SIGKILL")
 		if err != nil {
 			return err
 		}
@@ -66,7 +85,7 @@ func (c *client) RemoveContainer(ctx context.Context, ctn *pipeline.Container) e
 	}
 
 	// send API call to remove the container
-	err = c.Runtime.ContainerRemove(ctx, ctn.ID, opts)
+	err = c.Runtime.ContainerRemove(ctx, c.containerName(ctn), opts)
 	if err != nil {
 		return err
 	}
@@ -79,7 +98,36 @@ func (c *client) RunContainer(ctx context.Context, b *pipeline.Build, ctn *pipel
 	// create container configuration
 	ctnConf := ctnConfig(ctn)
 	// create host configuration
-	hostConf := hostConfig(b.ID)
+	hostConf := hostConfig(b.ID, mergeUlimits(c.Ulimits, ctn.Ulimits))
+
+	// check if the container requests to run privileged
+	if ctn.Privileged {
+		// check if the image is allowed to run privileged
+		allowed, err := isPrivilegedImage(ctn.Image, c.PrivilegedImages)
+		if err != nil {
+			return err
+		}
+
+		if !allowed {
+			return fmt.Errorf("image %s is not allowed to run privileged", ctn.Image)
+		}
+	}
+
+	hostConf.Privileged = ctn.Privileged
+
+	// create the mounts for the volumes declared for the container
+	mounts, err := volumeMounts(b.ID, ctn.Volumes, c.CacheVolumes)
+	if err != nil {
+		return err
+	}
+
+	hostConf.Mounts = append(hostConf.Mounts, mounts...)
+
+	// set the capabilities configured for the runtime since
+	// the container config has no capabilities of its own
+	hostConf.CapAdd = c.CapAdd
+	hostConf.CapDrop = c.CapDrop
+
 	// create network configuration
 	netConf := netConfig(b.ID, ctn.Name)
 
@@ -91,7 +139,7 @@ func (c *client) RunContainer(ctx context.Context, b *pipeline.Build, ctn *pipel
 		ctnConf,
 		hostConf,
 		netConf,
-		ctn.ID,
+		c.containerName(ctn),
 	)
 	if err != nil {
 		return err
@@ -121,27 +169,17 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 		return err
 	}
 
+	// capture the credentials for pulling the image
+	auth, err := c.registryAuth(image)
+	if err != nil {
+		return err
+	}
+
 	// check if the container should be updated
-	if ctn.Pull {
+	if c.PullPolicy == PullAlways || (ctn.Pull && c.PullPolicy != PullNever) {
 		logrus.Tracef("Pulling configured image %s", image)
-		// create options for pulling image
-		opts := types.ImagePullOptions{}
-
-		// send API call to pull the image for the container
-		reader, err := c.Runtime.ImagePull(ctx, image, opts)
-		if err != nil {
-			return err
-		}
 
-		defer reader.Close()
-
-		// copy output from image pull to standard output
-		_, err = io.Copy(os.Stdout, reader)
-		if err != nil {
-			return err
-		}
-
-		return nil
+		return c.pullImage(ctx, image, auth)
 	}
 
 	// check if the container image exists on the host
@@ -153,11 +191,26 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 	// if the container image does not exist on the host
 	// we attempt to capture it for executing the pipeline
 	if docker.IsErrNotFound(err) {
+		// check if the image is allowed to be pulled
+		if c.PullPolicy == PullNever {
+			return fmt.Errorf("image %s not found with pull policy %s", image, PullNever)
+		}
+
 		logrus.Tracef("Pulling unfound image %s", image)
 
-		// create options for pulling image
-		opts := types.ImagePullOptions{}
+		return c.pullImage(ctx, image, auth)
+	}
+
+	return err
+}
+
+// pullImage is a helper function to pull the image with the
+// provided credentials, retrying after a transient failure.
+func (c *client) pullImage(ctx context.Context, image, auth string) error {
+	// create options for pulling image
+	opts := types.ImagePullOptions{RegistryAuth: auth}
 
+	return c.retryPull(ctx, image, func() error {
 		// send API call to pull the image for the container
 		reader, err := c.Runtime.ImagePull(ctx, image, opts)
 		if err != nil {
@@ -173,12 +226,13 @@ func (c *client) SetupContainer(ctx context.Context, ctn *pipeline.Container) er
 		}
 
 		return nil
-	}
-
-	return err
+	})
 }
 
 // TailContainer captures the logs for the pipeline container.
+//
+// The log stream is reconnected after a transient error and
+// resumes from the last line that was captured.
 func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io.ReadCloser, error) {
 	logrus.Tracef("Capturing container logs for step %s", ctn.ID)
 
@@ -188,11 +242,11 @@ func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io
 		ShowStdout: true,
 		ShowStderr: true,
 		Details:    false,
-		Timestamps: false,
+		Timestamps: true,
 	}
 
 	// send API call to capture the container logs
-	logs, err := c.Runtime.ContainerLogs(ctx, ctn.ID, opts)
+	logs, err := c.Runtime.ContainerLogs(ctx, c.containerName(ctn), opts)
 	if err != nil {
 		return nil, err
 	}
@@ -204,10 +258,39 @@ func (c *client) TailContainer(ctx context.Context, ctn *pipeline.Container) (io
 
 	// capture all stdout and stderr logs
 	go func() {
-		stdcopy.StdCopy(wc, wc, logs)
-		logs.Close()
+		// create writer to remove timestamps and replayed lines
+		w := newTailWriter(wc)
+
+		for i := 0; ; i++ {
+			_, err := stdcopy.StdCopy(w, w, logs)
+			logs.Close()
+
+			// check if the log stream is complete
+			if err == nil || ctx.Err() != nil || i >= tailReconnects {
+				break
+			}
+
+			logrus.Debugf("unable to copy container logs for step %s: %v. Reconnecting", ctn.ID, err)
+
+			// discard the partial line replayed by the reconnect
+			w.Reset()
+
+			// resume the logs from the last line captured
+			opts.Since = w.Since()
+
+			// send API call to reconnect to the container logs
+			logs, err = c.Runtime.ContainerLogs(ctx, c.containerName(ctn), opts)
+			if err != nil {
+				logrus.Errorf("unable to reconnect to container logs for step %s: %v", ctn.ID, err)
+
+				break
+			}
+		}
+
+		// write the partial line left from the logs
+		w.Flush()
+
 		wc.Close()
-		rc.Close()
 	}()
 
 	return rc, nil
@@ -218,7 +301,7 @@ func (c *client) WaitContainer(ctx context.Context, ctn *pipeline.Container) err
 	logrus.Tracef("Waiting for container for step %s", ctn.ID)
 
 	// send API call to wait for the container completion
-	wait, errC := c.Runtime.ContainerWait(ctx, ctn.ID, container.WaitConditionNotRunning)
+	wait, errC := c.Runtime.ContainerWait(ctx, c.containerName(ctn), container.WaitConditionNotRunning)
 	select {
 	case <-wait:
 	case err := <-errC:
@@ -228,6 +311,12 @@ func (c *client) WaitContainer(ctx context.Context, ctn *pipeline.Container) err
 	return nil
 }
 
+// containerName is a helper function to create the name
+// of the container in the runtime from the configured prefix.
+func (c *client) containerName(ctn *pipeline.Container) string {
+	return c.ContainerPrefix + ctn.ID
+}
+
 // ctnConfig is a helper function to
 // generate the container config.
 func ctnConfig(ctn *pipeline.Container) *container.Config {
@@ -278,7 +367,7 @@ func ctnConfig(ctn *pipeline.Container) *container.Config {
 
 // hostConfig is a helper function to generate
 // the host config for a container.
-func hostConfig(id string) *container.HostConfig {
+func hostConfig(id string, ulimits []*units.Ulimit) *container.HostConfig {
 	return &container.HostConfig{
 		LogConfig: container.LogConfig{
 			Type: "json-file",
@@ -291,5 +380,49 @@ func hostConfig(id string) *container.HostConfig {
 				Target: "/home",
 			},
 		},
+		Resources: container.Resources{
+			Ulimits: ulimits,
+		},
 	}
 }
+
+// mergeUlimits is a helper function to combine the default
+// ulimits for the worker with the ulimits set for the step.
+//
+// Ulimits set for the step override the worker defaults
+// with the same name.
+func mergeUlimits(defaults []*units.Ulimit, step pipeline.UlimitSlice) []*units.Ulimit {
+	// check if the step sets any ulimits
+	if len(step) == 0 {
+		return defaults
+	}
+
+	ulimits := []*units.Ulimit{}
+
+	// capture the defaults not overridden by the step
+	for _, ulimit := range defaults {
+		overridden := false
+
+		for _, s := range step {
+			if strings.EqualFold(s.Name, ulimit.Name) {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			ulimits = append(ulimits, ulimit)
+		}
+	}
+
+	// add the ulimits set for the step
+	for _, s := range step {
+		ulimits = append(ulimits, &units.Ulimit{
+			Name: strings.ToLower(s.Name),
+			Soft: s.Soft,
+			Hard: s.Hard,
+		})
+	}
+
+	return ulimits
+}
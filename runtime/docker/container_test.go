@@ -5,10 +5,27 @@
 package docker
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-vela/types/pipeline"
+	"github.com/go-vela/worker/runtime"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	docker "github.com/docker/docker/client"
+	"github.com/docker/go-units"
+	"github.com/go-vela/worker/runtime/docker/testdata/mock"
 )
 
 func TestDocker_InspectContainer_Success(t *testing.T) {
@@ -42,6 +59,65 @@ func TestDocker_InspectContainer_Failure(t *testing.T) {
 	}
 }
 
+func TestDocker_InspectContainer_OOMKilled(t *testing.T) {
+	// setup Docker
+	c, _ := NewMock()
+
+	ctn := &pipeline.Container{
+		ID:    "container_oomkilled",
+		Image: "alpine:latest",
+	}
+
+	// run test
+	err := c.InspectContainer(context.Background(), ctn)
+	if !errors.Is(err, runtime.ErrOOMKilled) {
+		t.Errorf("InspectContainer returned err %v, want %v", err, runtime.ErrOOMKilled)
+	}
+
+	if ctn.ExitCode != 137 {
+		t.Errorf("ExitCode is %d, want 137", ctn.ExitCode)
+	}
+}
+
+func TestDocker_InspectContainer_Reason(t *testing.T) {
+	// setup Docker
+	c, _ := NewMock()
+
+	// setup tests
+	tests := []struct {
+		id   string
+		code int
+		want error
+	}{
+		{id: "container_id", code: 0, want: nil},
+		{id: "container_failed", code: 1, want: nil},
+		{id: "container_killed", code: 137, want: runtime.ErrKilled},
+		{id: "container_oomkilled", code: 137, want: runtime.ErrOOMKilled},
+	}
+
+	// run tests
+	for _, test := range tests {
+		ctn := &pipeline.Container{
+			ID:    test.id,
+			Image: "alpine:latest",
+		}
+
+		err := c.InspectContainer(context.Background(), ctn)
+
+		if test.want == nil && err != nil {
+			t.Errorf("InspectContainer for %s returned err: %v", test.id, err)
+		}
+
+		if test.want != nil && !errors.Is(err, test.want) {
+			t.Errorf("InspectContainer for %s returned err %v, want %v", test.id, err, test.want)
+		}
+
+		if ctn.ExitCode != test.code {
+			t.Errorf("InspectContainer for %s exit code is %d, want %d", test.id, ctn.ExitCode, test.code)
+		}
+	}
+}
+
 func TestDocker_RemoveContainer_Success(t *testing.T) {
 	// setup Docker
 	c, _ := NewMock()
@@ -114,6 +190,342 @@ func TestDocker_RunContainer_Failure(t *testing.T) {
 	}
 }
 
+func TestDocker_RunContainer_Ulimits(t *testing.T) {
+	// setup types
+	c, got := newHostConfigMock()
+
+	want := []*units.Ulimit{
+		{Name: "nofile", Soft: 1024, Hard: 65536},
+		{Name: "nproc", Soft: 2048, Hard: 2048},
+	}
+
+	_, err := c.WithUlimits([]string{"nofile=1024:65536", "nproc=2048"})
+	if err != nil {
+		t.Errorf("WithUlimits returned err: %v", err)
+	}
+
+	// run test
+	err = c.RunContainer(context.Background(),
+		&pipeline.Build{
+			Version: "1",
+			ID:      "__0",
+		},
+		&pipeline.Container{
+			ID:    "container_id",
+			Image: "alpine:latest",
+		})
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Ulimits, want) {
+		t.Errorf("Ulimits is %v, want %v", got.Ulimits, want)
+	}
+}
+
+func TestDocker_RunContainer_StepUlimits(t *testing.T) {
+	// setup types
+	c, got := newHostConfigMock()
+
+	want := []*units.Ulimit{
+		{Name: "nproc", Soft: 2048, Hard: 2048},
+		{Name: "nofile", Soft: 4096, Hard: 8192},
+		{Name: "memlock", Soft: -1, Hard: -1},
+	}
+
+	_, err := c.WithUlimits([]string{"nofile=1024:65536", "nproc=2048"})
+	if err != nil {
+		t.Errorf("WithUlimits returned err: %v", err)
+	}
+
+	// run test
+	err = c.RunContainer(context.Background(),
+		&pipeline.Build{
+			Version: "1",
+			ID:      "__0",
+		},
+		&pipeline.Container{
+			ID:    "container_id",
+			Image: "alpine:latest",
+			Ulimits: pipeline.UlimitSlice{
+				{Name: "nofile", Soft: 4096, Hard: 8192},
+				{Name: "memlock", Soft: -1, Hard: -1},
+			},
+		})
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual(got.Ulimits, want) {
+		t.Errorf("Ulimits is %v, want %v", got.Ulimits, want)
+	}
+}
+
+func TestDocker_RunContainer_Capabilities(t *testing.T) {
+	// setup types
+	c, got := newHostConfigMock()
+
+	wantAdd := []string{"CHOWN", "NET_BIND_SERVICE"}
+	wantDrop := []string{"ALL"}
+
+	c.WithCapabilities(wantAdd, wantDrop)
+
+	// run test
+	err := c.RunContainer(context.Background(),
+		&pipeline.Build{
+			Version: "1",
+			ID:      "__0",
+		},
+		&pipeline.Container{
+			ID:    "container_id",
+			Image: "alpine:latest",
+		})
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	if !reflect.DeepEqual([]string(got.CapAdd), wantAdd) {
+		t.Errorf("CapAdd is %v, want %v", got.CapAdd, wantAdd)
+	}
+
+	if !reflect.DeepEqual([]string(got.CapDrop), wantDrop) {
+		t.Errorf("CapDrop is %v, want %v", got.CapDrop, wantDrop)
+	}
+}
+
+func TestDocker_Container_Prefix(t *testing.T) {
+	// setup types
+	var (
+		created string
+		removed []string
+	)
+
+	// capture the container names for creating and removing the container
+	doer := func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/containers/create") {
+			created = r.URL.Query().Get("name")
+		}
+
+		if r.Method == http.MethodDelete {
+			removed = append(removed, path.Base(r.URL.Path))
+		}
+
+		return mock.Router(r)
+	}
+
+	r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+	c := &client{Runtime: r}
+
+	_, err := c.WithContainerPrefix("worker-1.")
+	if err != nil {
+		t.Errorf("WithContainerPrefix returned err: %v", err)
+	}
+
+	ctn := &pipeline.Container{
+		ID:    "step_github_octocat_1_clone",
+		Image: "alpine:latest",
+	}
+
+	want := "worker-1.step_github_octocat_1_clone"
+
+	// run test
+	err = c.RunContainer(context.Background(), &pipeline.Build{Version: "1", ID: "__0"}, ctn)
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	err = c.RemoveContainer(context.Background(), ctn)
+	if err != nil {
+		t.Errorf("RemoveContainer returned err: %v", err)
+	}
+
+	if created != want {
+		t.Errorf("RunContainer created container %s, want %s", created, want)
+	}
+
+	if !reflect.DeepEqual(removed, []string{want}) {
+		t.Errorf("RemoveContainer removed containers %v, want [%s]", removed, want)
+	}
+}
+
+func TestDocker_WithContainerPrefix_Invalid(t *testing.T) {
+	// setup types
+	c, _ := NewMock()
+
+	// run test
+	_, err := c.WithContainerPrefix("-worker/")
+	if err == nil {
+		t.Errorf("WithContainerPrefix should have returned err")
+	}
+
+	if len(c.ContainerPrefix) > 0 {
+		t.Errorf("ContainerPrefix is %s, want empty", c.ContainerPrefix)
+	}
+}
+
+func TestDocker_RunContainer_Privileged(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		image      string
+		privileged bool
+		want       bool
+		failure    bool
+	}{
+		{image: "target/vela-docker:latest", privileged: true, want: true},
+		{image: "target/vela-docker:latest", privileged: false, want: false},
+		{image: "alpine:latest", privileged: false, want: false},
+		{image: "alpine:latest", privileged: true, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, got := newHostConfigMock()
+
+		_, err := c.WithPrivilegedImages([]string{"target/vela-docker"})
+		if err != nil {
+			t.Errorf("WithPrivilegedImages returned err: %v", err)
+		}
+
+		err = c.RunContainer(context.Background(),
+			&pipeline.Build{
+				Version: "1",
+				ID:      "__0",
+			},
+			&pipeline.Container{
+				ID:         "container_id",
+				Image:      test.image,
+				Privileged: test.privileged,
+			})
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("RunContainer for privileged %s should have returned err", test.image)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("RunContainer for %s returned err: %v", test.image, err)
+		}
+
+		if got.Privileged != test.want {
+			t.Errorf("Privileged for %s is %v, want %v", test.image, got.Privileged, test.want)
+		}
+	}
+}
+
+func TestDocker_RunContainer_NetworkAlias(t *testing.T) {
+	// setup types
+	var got network.NetworkingConfig
+
+	// capture the network config for creating the container
+	doer := func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/containers/create") {
+			body := struct {
+				NetworkingConfig network.NetworkingConfig
+			}{}
+
+			err := json.NewDecoder(r.Body).Decode(&body)
+			if err != nil {
+				t.Errorf("unable to decode container config: %v", err)
+			}
+
+			got = body.NetworkingConfig
+		}
+
+		return mock.Router(r)
+	}
+
+	r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+	c := &client{Runtime: r}
+
+	// run test
+	err := c.RunContainer(context.Background(), &pipeline.Build{
+		Version: "1",
+		ID:      "__0",
+	}, &pipeline.Container{
+		ID:     "service_github_octocat_1_postgres",
+		Detach: true,
+		Image:  "postgres:12-alpine",
+		Name:   "postgres",
+		Number: 1,
+	})
+	if err != nil {
+		t.Errorf("RunContainer returned err: %v", err)
+	}
+
+	endpoint, ok := got.EndpointsConfig["__0"]
+	if !ok {
+		t.Fatalf("RunContainer should have attached the container to the build network")
+	}
+
+	if !reflect.DeepEqual(endpoint.Aliases, []string{"postgres"}) {
+		t.Errorf("RunContainer network aliases are %v, want [postgres]", endpoint.Aliases)
+	}
+}
+
+func TestDocker_RunContainer_Volumes(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		volume  *pipeline.Volume
+		want    mount.Mount
+	}{
+		{
+			volume: &pipeline.Volume{Source: "__0", Destination: "/vela/src"},
+			want:   mount.Mount{Type: mount.TypeVolume, Source: "__0", Target: "/vela/src"},
+		},
+		{
+			volume: &pipeline.Volume{Source: "/var/cache/vela/npm", Destination: "/root/.npm", AccessMode: "ro"},
+			want:   mount.Mount{Type: mount.TypeBind, Source: "/var/cache/vela/npm", Target: "/root/.npm", ReadOnly: true},
+		},
+		{
+			failure: true,
+			volume:  &pipeline.Volume{Source: "/home/vela", Destination: "/root/.npm"},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, got := newHostConfigMock()
+
+		_, err := c.WithCacheVolumes([]string{"/var/cache/vela"})
+		if err != nil {
+			t.Errorf("WithCacheVolumes returned err: %v", err)
+		}
+
+		err = c.RunContainer(context.Background(),
+			&pipeline.Build{
+				Version: "1",
+				ID:      "__0",
+			},
+			&pipeline.Container{
+				ID:      "container_id",
+				Image:   "alpine:latest",
+				Volumes: pipeline.VolumeSlice{test.volume},
+			})
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("RunContainer with volume %s should have returned err", test.volume.Source)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("RunContainer returned err: %v", err)
+		}
+
+		if len(got.Mounts) != 2 || !reflect.DeepEqual(got.Mounts[1], test.want) {
+			t.Errorf("RunContainer mounts are %v, want the build volume and %v", got.Mounts, test.want)
+		}
+	}
+}
+
 func TestDocker_SetupContainer_Success(t *testing.T) {
 	// setup Docker
 	c, _ := NewMock()
@@ -130,6 +542,65 @@ func TestDocker_SetupContainer_Success(t *testing.T) {
 	}
 }
 
+func TestDocker_SetupContainer_PullPolicy(t *testing.T) {
+	// setup types
+	var pulls int
+
+	// count the requests to pull the image
+	doer := func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/images/create") {
+			pulls++
+		}
+
+		return mock.Router(r)
+	}
+
+	// setup Docker
+	r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+	// setup tests
+	tests := []struct {
+		policy  string
+		pull    bool
+		image   string
+		want    int
+		failure bool
+	}{
+		{policy: PullAlways, pull: false, image: "alpine:latest", want: 1},
+		{policy: PullIfNotPresent, pull: true, image: "alpine:latest", want: 1},
+		{policy: PullIfNotPresent, pull: false, image: "alpine:latest", want: 0},
+		{policy: PullIfNotPresent, pull: false, image: "alpine:notfound", want: 1},
+		{policy: PullNever, pull: true, image: "alpine:latest", want: 0},
+		{policy: PullNever, pull: false, image: "alpine:notfound", want: 0, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		pulls = 0
+
+		c := &client{Runtime: r}
+		c.WithPullPolicy(test.policy)
+
+		err := c.SetupContainer(context.Background(), &pipeline.Container{
+			ID:    "container_id",
+			Image: test.image,
+			Pull:  test.pull,
+		})
+
+		if test.failure && err == nil {
+			t.Errorf("SetupContainer for %s policy should have returned err", test.policy)
+		}
+
+		if !test.failure && err != nil {
+			t.Errorf("SetupContainer for %s policy returned err: %v", test.policy, err)
+		}
+
+		if pulls != test.want {
+			t.Errorf("SetupContainer for %s policy pulled %d times, want %d", test.policy, pulls, test.want)
+		}
+	}
+}
+
 func TestDocker_SetupContainer_Failure(t *testing.T) {
 	// setup Docker
 	c, _ := NewMock()
@@ -161,6 +632,33 @@ func TestDocker_TailContainer_Success(t *testing.T) {
 	}
 }
 
+func TestDocker_TailContainer_Reconnect(t *testing.T) {
+	// setup Docker
+	c, _ := NewMock()
+
+	want := "Hello, Docker 1\nHello, Docker 2\nHello, Docker 3\n"
+
+	// run test
+	rc, err := c.TailContainer(context.Background(), &pipeline.Container{
+		ID:    "container_interrupted",
+		Image: "alpine:latest",
+	})
+	if err != nil {
+		t.Errorf("TailContainer returned err: %v", err)
+	}
+
+	defer rc.Close()
+
+	got, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Errorf("unable to read container logs: %v", err)
+	}
+
+	if string(got) != want {
+		t.Errorf("TailContainer logs are %q, want %q", got, want)
+	}
+}
+
 // TODO: rethink how the mock is being done in the
 // router switch. This current gives false positives
 func TestDocker_TailContainer_Failure(t *testing.T) {
@@ -206,3 +704,102 @@ func TestDocker_WaitContainer_Failure(t *testing.T) {
 		t.Errorf("WaitContainer should have returned err: %+v", got)
 	}
 }
+
+func TestDocker_ctnConfig_Overrides(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		entrypoint []string
+		commands   []string
+		wantEntry  strslice.StrSlice
+		wantCmd    strslice.StrSlice
+	}{
+		{
+			entrypoint: []string{"/bin/sh", "-c"},
+			commands:   []string{"echo hello"},
+			wantEntry:  strslice.StrSlice{"/bin/sh", "-c"},
+			wantCmd:    strslice.StrSlice{"echo hello"},
+		},
+		{
+			entrypoint: nil,
+			commands:   []string{"echo hello"},
+			wantEntry:  nil,
+			wantCmd:    strslice.StrSlice{"echo hello"},
+		},
+		{
+			entrypoint: []string{},
+			commands:   []string{},
+			wantEntry:  nil,
+			wantCmd:    nil,
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := ctnConfig(&pipeline.Container{
+			ID:         "container_id",
+			Image:      "alpine:latest",
+			Entrypoint: test.entrypoint,
+			Commands:   test.commands,
+		})
+
+		if !reflect.DeepEqual(got.Entrypoint, test.wantEntry) {
+			t.Errorf("Entrypoint is %v, want %v", got.Entrypoint, test.wantEntry)
+		}
+
+		if !reflect.DeepEqual(got.Cmd, test.wantCmd) {
+			t.Errorf("Cmd is %v, want %v", got.Cmd, test.wantCmd)
+		}
+	}
+}
+
+func TestDocker_ctnConfig_WorkingDir(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		directory string
+		want      string
+	}{
+		{directory: "/vela/src/github.com/octocat/hello-world", want: "/vela/src/github.com/octocat/hello-world"},
+		{directory: "", want: ""},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := ctnConfig(&pipeline.Container{
+			ID:        "container_id",
+			Image:     "alpine:latest",
+			Directory: test.directory,
+		})
+
+		if got.WorkingDir != test.want {
+			t.Errorf("WorkingDir is %v, want %v", got.WorkingDir, test.want)
+		}
+	}
+}
+
+// newHostConfigMock is a helper function to create a mock
+// Docker runtime that captures the host config sent
+// when creating a container.
+func newHostConfigMock() (*client, *container.HostConfig) {
+	got := new(container.HostConfig)
+
+	// capture the host config sent when creating the container
+	doer := func(r *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(r.URL.Path, "/containers/create") {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			config := struct {
+				HostConfig *container.HostConfig
+			}{HostConfig: got}
+
+			_ = json.Unmarshal(body, &config)
+
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+
+		return mock.Router(r)
+	}
+
+	r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+	return &client{Runtime: r}, got
+}
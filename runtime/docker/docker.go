@@ -5,15 +5,86 @@
 package docker
 
 import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/go-vela/types/constants"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"github.com/go-vela/worker/runtime/docker/testdata/mock"
 	"github.com/sirupsen/logrus"
 )
 
 const dockerVersion = "1.38"
 
+const (
+	// PullAlways defines the pull policy that
+	// always pulls the image for a container.
+	PullAlways = "always"
+
+	// PullIfNotPresent defines the pull policy that only pulls
+	// the image for a container if it is configured to be
+	// updated or does not exist on the host.
+	PullIfNotPresent = "if-not-present"
+
+	// PullNever defines the pull policy that never
+	// pulls the image for a container.
+	PullNever = "never"
+)
+
+const (
+	// defaultPullRetries defines the default number of times
+	// pulling an image is retried after a transient failure.
+	defaultPullRetries = 3
+
+	// defaultPullRetryBackoff defines the default amount of time
+	// waited before the first retry of pulling an image.
+	defaultPullRetryBackoff = time.Second
+)
+
+// containerPrefix defines the characters
+// allowed in the prefix for a container name.
+var containerPrefix = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
 type client struct {
 	Runtime *docker.Client
+
+	// CacheVolumes defines the allowlist of host paths
+	// and named volumes a step is able to mount.
+	CacheVolumes []string
+	// CapAdd defines the Linux capabilities
+	// added to every container created.
+	CapAdd []string
+	// CapDrop defines the Linux capabilities
+	// dropped from every container created.
+	CapDrop []string
+	// ContainerPrefix defines the prefix added to the name
+	// of every container to avoid collisions with other
+	// containers on the host.
+	ContainerPrefix string
+	// Credentials defines the credentials used for pulling
+	// images from a registry, keyed by the registry domain.
+	Credentials map[string]types.AuthConfig
+	// PrivilegedImages defines the allowlist of
+	// images that are able to run privileged.
+	PrivilegedImages []string
+	// PullPolicy defines the policy for pulling the
+	// image for a container before it is created.
+	PullPolicy string
+	// PullRetries defines the number of times pulling
+	// an image is retried after a transient failure.
+	PullRetries int
+	// PullRetryBackoff defines the amount of time waited
+	// before the first retry of pulling an image.
+	PullRetryBackoff time.Duration
+	// Ulimits defines the resource limits
+	// applied to every container created.
+	Ulimits []*units.Ulimit
 }
 
 // New returns an Engine implementation that
@@ -27,11 +98,17 @@ func New() (*client, error) {
 	// pin version to prevent "client version <version> is too new." errors
 	// typically this would be inherited from the host env but this will ensure
 	// we know what version of the Docker API we're using
-	docker.WithVersion(dockerVersion)(r)
+	err = docker.WithVersion(dockerVersion)(r)
+	if err != nil {
+		return nil, err
+	}
 
 	// create the client object
 	c := &client{
-		Runtime: r,
+		Runtime:          r,
+		PullPolicy:       PullIfNotPresent,
+		PullRetries:      defaultPullRetries,
+		PullRetryBackoff: defaultPullRetryBackoff,
 	}
 
 	return c, nil
@@ -53,8 +130,125 @@ func NewMock() (*client, error) {
 
 	// create the client object
 	c := &client{
-		Runtime: r,
+		Runtime:          r,
+		PullPolicy:       PullIfNotPresent,
+		PullRetries:      defaultPullRetries,
+		PullRetryBackoff: defaultPullRetryBackoff,
 	}
 
 	return c, nil
 }
+
+// WithCacheVolumes sets the allowlist of host paths
+// and named volumes a step is able to mount in the Runtime.
+func (c *client) WithCacheVolumes(paths []string) (*client, error) {
+	// validate each of the provided paths
+	for _, path := range paths {
+		if !filepath.IsAbs(path) && !volumeName.MatchString(path) {
+			return c, fmt.Errorf("cache volume %s must be an absolute path or a volume name", path)
+		}
+	}
+
+	c.CacheVolumes = paths
+
+	return c, nil
+}
+
+// WithCapabilities sets the Linux capabilities added to
+// and dropped from every container in the Runtime.
+//
+// Capabilities are dropped before they are added, so
+// dropping "ALL" and adding a few grants only those.
+//
+// The capabilities apply to the whole worker since the
+// pipeline container has no capabilities for a step.
+func (c *client) WithCapabilities(add, drop []string) *client {
+	c.CapAdd = add
+	c.CapDrop = drop
+
+	return c
+}
+
+// WithContainerPrefix sets the prefix added to
+// the name of every container in the Runtime.
+func (c *client) WithContainerPrefix(prefix string) (*client, error) {
+	// validate the provided prefix is allowed in a container name
+	if len(prefix) > 0 && !containerPrefix.MatchString(prefix) {
+		return c, fmt.Errorf("invalid container prefix %s", prefix)
+	}
+
+	c.ContainerPrefix = prefix
+
+	return c, nil
+}
+
+// WithPrivilegedImages sets the allowlist of images
+// that are able to run privileged in the Runtime.
+func (c *client) WithPrivilegedImages(images []string) (*client, error) {
+	// validate each of the provided images
+	for _, image := range images {
+		_, err := reference.ParseNormalizedNamed(image)
+		if err != nil {
+			return c, fmt.Errorf("unable to parse privileged image %s: %w", image, err)
+		}
+	}
+
+	c.PrivilegedImages = images
+
+	return c, nil
+}
+
+// WithPullPolicy sets the policy for pulling
+// the image for a container in the Runtime.
+func (c *client) WithPullPolicy(policy string) *client {
+	// set pull policy in runtime if a valid one is provided
+	switch policy {
+	case PullAlways, PullIfNotPresent, PullNever:
+		c.PullPolicy = policy
+	}
+
+	return c
+}
+
+// WithPullRetries sets the number of times pulling an
+// image is retried after a transient failure in the Runtime.
+func (c *client) WithPullRetries(retries int) *client {
+	// set pull retries in runtime if a valid one is provided
+	if retries >= 0 {
+		c.PullRetries = retries
+	}
+
+	return c
+}
+
+// WithPullRetryBackoff sets the amount of time waited before
+// the first retry of pulling an image in the Runtime.
+func (c *client) WithPullRetryBackoff(backoff time.Duration) *client {
+	// set pull retry backoff in runtime if a valid one is provided
+	if backoff >= 0 {
+		c.PullRetryBackoff = backoff
+	}
+
+	return c
+}
+
+// WithUlimits sets the resource limits
+// applied to every container in the Runtime.
+func (c *client) WithUlimits(ulimits []string) (*client, error) {
+	// parse each of the provided ulimits
+	for _, ulimit := range ulimits {
+		u, err := units.ParseUlimit(ulimit)
+		if err != nil {
+			return c, fmt.Errorf("unable to parse ulimit %s: %w", ulimit, err)
+		}
+
+		c.Ulimits = append(c.Ulimits, u)
+	}
+
+	return c, nil
+}
+
+// Name returns the name of the Docker runtime.
+func (c *client) Name() string {
+	return constants.DriverDocker
+}
@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"testing"
+)
+
+func TestDocker_Name(t *testing.T) {
+	// setup types
+	c, _ := NewMock()
+
+	want := "docker"
+
+	// run test
+	got := c.Name()
+
+	if got != want {
+		t.Errorf("Name is %v, want %v", got, want)
+	}
+}
+
+func TestDocker_WithPullPolicy(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{policy: PullAlways, want: PullAlways},
+		{policy: PullIfNotPresent, want: PullIfNotPresent},
+		{policy: PullNever, want: PullNever},
+		{policy: "sometimes", want: PullIfNotPresent},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, _ := NewMock()
+
+		got := c.WithPullPolicy(test.policy).PullPolicy
+
+		if got != test.want {
+			t.Errorf("WithPullPolicy for %s is %v, want %v", test.policy, got, test.want)
+		}
+	}
+}
+
+func TestDocker_WithUlimits(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		ulimits []string
+		want    int
+		failure bool
+	}{
+		{ulimits: []string{"nofile=1024:65536"}, want: 1},
+		{ulimits: []string{"nofile=1024:65536", "nproc=2048"}, want: 2},
+		{ulimits: []string{}, want: 0},
+		{ulimits: []string{"nofile"}, failure: true},
+		{ulimits: []string{"foo=1024"}, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, _ := NewMock()
+
+		_, err := c.WithUlimits(test.ulimits)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithUlimits for %v should have returned err", test.ulimits)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithUlimits for %v returned err: %v", test.ulimits, err)
+		}
+
+		if len(c.Ulimits) != test.want {
+			t.Errorf("WithUlimits for %v is %d ulimits, want %d", test.ulimits, len(c.Ulimits), test.want)
+		}
+	}
+}
+
+func TestDocker_WithCacheVolumes(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failure bool
+		volumes []string
+	}{
+		{volumes: []string{"/var/cache/vela", "shared"}},
+		{failure: true, volumes: []string{"var/cache/vela"}},
+		{failure: true, volumes: []string{"-shared"}},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, _ := NewMock()
+
+		_, err := c.WithCacheVolumes(test.volumes)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("WithCacheVolumes for %v should have returned err", test.volumes)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("WithCacheVolumes for %v returned err: %v", test.volumes, err)
+		}
+	}
+}
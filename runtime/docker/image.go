@@ -45,3 +45,47 @@ func parseImage(s string) (string, error) {
 	// add latest tag to image if no tag was provided
 	return reference.TagNameOnly(image).String(), nil
 }
+
+// isPrivilegedImage is a helper function to check if the
+// image for the provided container is in the allowlist of
+// images that are able to run privileged.
+//
+// An allowlisted image without a tag matches every tag
+// for the image, otherwise the tag must match as well.
+func isPrivilegedImage(s string, privileged []string) (bool, error) {
+	// check if no images are allowed to run privileged
+	if len(privileged) == 0 {
+		return false, nil
+	}
+
+	// parse image from container
+	image, err := reference.ParseNormalizedNamed(s)
+	if err != nil {
+		return false, err
+	}
+
+	image = reference.TagNameOnly(image)
+
+	for _, p := range privileged {
+		// parse image from the allowlist
+		allowed, err := reference.ParseNormalizedNamed(p)
+		if err != nil {
+			return false, err
+		}
+
+		// check if the allowlisted image has a tag
+		if reference.IsNameOnly(allowed) {
+			if allowed.Name() == image.Name() {
+				return true, nil
+			}
+
+			continue
+		}
+
+		if allowed.String() == image.String() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
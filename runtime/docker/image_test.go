@@ -73,3 +73,46 @@ func TestDocker_InspectImage_NoImage(t *testing.T) {
 		t.Errorf("InspectImage is %v, want nil", got)
 	}
 }
+
+func TestDocker_isPrivilegedImage(t *testing.T) {
+	// setup types
+	privileged := []string{"target/vela-docker", "docker:dind", "registry.example.com/org/image:1"}
+
+	// setup tests
+	tests := []struct {
+		image string
+		want  bool
+	}{
+		{image: "target/vela-docker", want: true},
+		{image: "target/vela-docker:v0.1.0", want: true},
+		{image: "docker.io/target/vela-docker:latest", want: true},
+		{image: "docker:dind", want: true},
+		{image: "docker:latest", want: false},
+		{image: "registry.example.com/org/image:1", want: true},
+		{image: "registry.example.com/org/image:2", want: false},
+		{image: "alpine:latest", want: false},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := isPrivilegedImage(test.image, privileged)
+		if err != nil {
+			t.Errorf("isPrivilegedImage for %s returned err: %v", test.image, err)
+		}
+
+		if got != test.want {
+			t.Errorf("isPrivilegedImage for %s is %v, want %v", test.image, got, test.want)
+		}
+	}
+}
+
+func TestDocker_WithPrivilegedImages_Invalid(t *testing.T) {
+	// setup Docker
+	c, _ := NewMock()
+
+	// run test
+	_, err := c.WithPrivilegedImages([]string{"!@#$%^&*()"})
+	if err == nil {
+		t.Errorf("WithPrivilegedImages should have returned err")
+	}
+}
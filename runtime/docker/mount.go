@@ -0,0 +1,116 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+// volumeName defines the characters
+// allowed in the name of a volume.
+var volumeName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// volumeMounts is a helper function to create the mounts
+// for the volumes declared for the container.
+//
+// The build volume, referenced by the ID of the build, can
+// be mounted at any path. Host paths and other named volumes
+// must be permitted by the allowlist.
+func volumeMounts(id string, volumes pipeline.VolumeSlice, allowed []string) ([]mount.Mount, error) {
+	// check if the container declared any volumes
+	if len(volumes) == 0 {
+		return nil, nil
+	}
+
+	mounts := []mount.Mount{}
+
+	for _, v := range volumes {
+		// check if the paths for the volume are provided
+		if len(v.Source) == 0 || len(v.Destination) == 0 {
+			return nil, fmt.Errorf("invalid volume %s:%s: source and destination must be provided", v.Source, v.Destination)
+		}
+
+		target := filepath.Clean(v.Destination)
+
+		// check if the path in the container is absolute
+		if !filepath.IsAbs(target) {
+			return nil, fmt.Errorf("invalid volume %s:%s: destination must be absolute", v.Source, v.Destination)
+		}
+
+		m := mount.Mount{
+			Type:   mount.TypeVolume,
+			Source: v.Source,
+			Target: target,
+		}
+
+		// set the access mode for the volume
+		switch v.AccessMode {
+		case "", "rw":
+		case "ro":
+			m.ReadOnly = true
+		default:
+			return nil, fmt.Errorf("invalid volume %s:%s: unsupported access mode %s", v.Source, v.Destination, v.AccessMode)
+		}
+
+		switch {
+		// the build volume is always permitted
+		case v.Source == id:
+		// host paths are mounted with a bind mount
+		case filepath.IsAbs(v.Source):
+			m.Type = mount.TypeBind
+			m.Source = filepath.Clean(v.Source)
+
+			// check if the host path is permitted to be mounted
+			if !isAllowedPath(m.Source, allowed) {
+				return nil, fmt.Errorf("volume %s is not allowed on this worker", v.Source)
+			}
+		// named volumes must be in the allowlist
+		case !isAllowedVolume(v.Source, allowed):
+			return nil, fmt.Errorf("volume %s is not allowed on this worker", v.Source)
+		}
+
+		mounts = append(mounts, m)
+	}
+
+	return mounts, nil
+}
+
+// isAllowedPath is a helper function to check if the
+// host path is, or is within, a path in the allowlist.
+func isAllowedPath(path string, allowed []string) bool {
+	for _, a := range allowed {
+		a = filepath.Clean(a)
+
+		if path == a || strings.HasPrefix(path, strings.TrimSuffix(a, "/")+"/") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isAllowedVolume is a helper function to check if
+// the named volume is in the allowlist.
+func isAllowedVolume(name string, allowed []string) bool {
+	// check if the name is valid for a volume
+	if !volumeName.MatchString(name) {
+		return false
+	}
+
+	for _, a := range allowed {
+		if name == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,96 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	"github.com/docker/docker/api/types/mount"
+)
+
+func TestDocker_volumeMounts(t *testing.T) {
+	// setup types
+	allowed := []string{"/var/cache/vela", "/opt/go/", "shared"}
+
+	// setup tests
+	tests := []struct {
+		failure bool
+		volumes pipeline.VolumeSlice
+		want    []mount.Mount
+	}{
+		{
+			volumes: nil,
+			want:    nil,
+		},
+		{
+			volumes: pipeline.VolumeSlice{{Source: "__0", Destination: "/vela/src", AccessMode: "rw"}},
+			want:    []mount.Mount{{Type: mount.TypeVolume, Source: "__0", Target: "/vela/src"}},
+		},
+		{
+			volumes: pipeline.VolumeSlice{
+				{Source: "/var/cache/vela/npm", Destination: "/root/.npm"},
+				{Source: "/opt/go/pkg", Destination: "/go/pkg/", AccessMode: "ro"},
+				{Source: "shared", Destination: "/shared", AccessMode: "ro"},
+			},
+			want: []mount.Mount{
+				{Type: mount.TypeBind, Source: "/var/cache/vela/npm", Target: "/root/.npm"},
+				{Type: mount.TypeBind, Source: "/opt/go/pkg", Target: "/go/pkg", ReadOnly: true},
+				{Type: mount.TypeVolume, Source: "shared", Target: "/shared", ReadOnly: true},
+			},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/etc", Destination: "/cache"}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/var/cache/vela/../../../etc", Destination: "/cache"}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/var/cache/velacache", Destination: "/cache"}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "__1", Destination: "/cache"}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/var/cache/vela", Destination: "cache"}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/var/cache/vela", Destination: ""}},
+		},
+		{
+			failure: true,
+			volumes: pipeline.VolumeSlice{{Source: "/var/cache/vela", Destination: "/cache", AccessMode: "rx"}},
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got, err := volumeMounts("__0", test.volumes, allowed)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("volumeMounts for %v should have returned err", test.volumes)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("volumeMounts for %v returned err: %v", test.volumes, err)
+		}
+
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("volumeMounts for %v is %v, want %v", test.volumes, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"context"
+	"time"
+
+	docker "github.com/docker/docker/client"
+
+	"github.com/sirupsen/logrus"
+)
+
+// retryPull is a helper function to retry pulling an
+// image with backoff after a transient failure.
+//
+// Images that are not found are not retried since pulling
+// the same image again is not expected to succeed.
+func (c *client) retryPull(ctx context.Context, image string, pull func() error) error {
+	backoff := c.PullRetryBackoff
+
+	for i := 0; ; i++ {
+		// send the pull to the Docker daemon
+		err := pull()
+		if err == nil {
+			return nil
+		}
+
+		// check if the error is not retryable
+		if docker.IsErrNotFound(err) {
+			return err
+		}
+
+		// check if all retries have been attempted
+		if i >= c.PullRetries {
+			return err
+		}
+
+		logrus.Debugf("unable to pull image %s: %v. Retrying in %v", image, err, backoff)
+
+		// wait for the backoff unless the context is canceled
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		// double the backoff for the next retry
+		backoff *= 2
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/go-vela/types/pipeline"
+
+	docker "github.com/docker/docker/client"
+	"github.com/go-vela/worker/runtime/docker/testdata/mock"
+)
+
+func TestDocker_SetupContainer_PullRetry(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		failures int
+		status   int
+		want     int
+		failure  bool
+	}{
+		{failures: 0, status: http.StatusInternalServerError, want: 1},
+		{failures: 2, status: http.StatusInternalServerError, want: 3},
+		{failures: 5, status: http.StatusInternalServerError, want: 4, failure: true},
+		{failures: 5, status: http.StatusNotFound, want: 1, failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		var got int
+
+		// fail pulling the image for the configured number of attempts
+		doer := func(r *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(r.URL.Path, "/images/create") {
+				got++
+
+				if got <= test.failures {
+					return &http.Response{
+						StatusCode: test.status,
+						Body:       ioutil.NopCloser(bytes.NewReader([]byte("unable to pull image"))),
+					}, nil
+				}
+			}
+
+			return mock.Router(r)
+		}
+
+		r, _ := docker.NewClient("tcp://127.0.0.1:2333", dockerVersion, mock.Client(doer), nil)
+
+		c := &client{Runtime: r, PullPolicy: PullAlways}
+		c.WithPullRetries(3)
+		c.WithPullRetryBackoff(0)
+
+		err := c.SetupContainer(context.Background(), &pipeline.Container{
+			ID:    "container_id",
+			Image: "alpine:latest",
+		})
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("SetupContainer with %d failures should have returned err", test.failures)
+			}
+		} else if err != nil {
+			t.Errorf("SetupContainer with %d failures returned err: %v", test.failures, err)
+		}
+
+		if got != test.want {
+			t.Errorf("SetupContainer with %d failures pulled %d times, want %d", test.failures, got, test.want)
+		}
+	}
+}
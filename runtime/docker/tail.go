@@ -0,0 +1,106 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"bytes"
+	"io"
+	"time"
+)
+
+// tailReconnects defines the number of times the log
+// stream for a container is reconnected after an error.
+const tailReconnects = 3
+
+// tailWriter is a writer that removes the timestamps Docker
+// adds to each log line and drops lines that were already
+// written before the log stream was reconnected.
+type tailWriter struct {
+	w    io.Writer
+	buf  []byte
+	last time.Time
+
+	// resumed is set while lines replayed after
+	// a reconnect are being skipped.
+	resumed bool
+}
+
+// newTailWriter returns a tailWriter that writes to w.
+func newTailWriter(w io.Writer) *tailWriter {
+	return &tailWriter{w: w}
+}
+
+// Write buffers the output and writes each complete line.
+func (t *tailWriter) Write(p []byte) (int, error) {
+	t.buf = append(t.buf, p...)
+
+	for {
+		// check if the buffer contains a complete line
+		i := bytes.IndexByte(t.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+
+		line := t.buf[:i+1]
+		t.buf = t.buf[i+1:]
+
+		err := t.writeLine(line)
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Since returns the timestamp of the last line written
+// for resuming the log stream after a reconnect.
+func (t *tailWriter) Since() string {
+	return t.last.Format(time.RFC3339Nano)
+}
+
+// Reset discards the partial line from the interrupted log
+// stream since Docker replays the whole line on reconnect.
+//
+// Lines up to the last timestamp written are skipped until
+// the first newer line is received from the resumed stream.
+func (t *tailWriter) Reset() {
+	t.buf = nil
+	t.resumed = true
+}
+
+// Flush writes the partial line left in the buffer.
+func (t *tailWriter) Flush() error {
+	if len(t.buf) == 0 {
+		return nil
+	}
+
+	line := t.buf
+	t.buf = nil
+
+	return t.writeLine(line)
+}
+
+// writeLine is a helper function to write the line without
+// the timestamp unless the line was already written.
+func (t *tailWriter) writeLine(line []byte) error {
+	// split the timestamp from the line
+	i := bytes.IndexByte(line, ' ')
+	if i > 0 {
+		timestamp, err := time.Parse(time.RFC3339Nano, string(line[:i]))
+		if err == nil {
+			// check if the line was written before the reconnect
+			if t.resumed && !timestamp.After(t.last) {
+				return nil
+			}
+
+			t.resumed = false
+			t.last = timestamp
+			line = line[i+1:]
+		}
+	}
+
+	_, err := t.w.Write(line)
+
+	return err
+}
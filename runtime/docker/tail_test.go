@@ -0,0 +1,107 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package docker
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDocker_tailWriter(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		name   string
+		writes []string
+		want   string
+	}{
+		{
+			name:   "timestamps removed",
+			writes: []string{"2020-01-01T00:00:01Z foo\n2020-01-01T00:00:02Z bar\n"},
+			want:   "foo\nbar\n",
+		},
+		{
+			name:   "line split across writes",
+			writes: []string{"2020-01-01T00:00:01Z f", "oo\n"},
+			want:   "foo\n",
+		},
+		{
+			name:   "lines with equal timestamps kept",
+			writes: []string{"2020-01-01T00:00:01Z foo\n", "2020-01-01T00:00:01Z foo\n2020-01-01T00:00:01Z bar\n"},
+			want:   "foo\nfoo\nbar\n",
+		},
+		{
+			name:   "lines with earlier timestamps kept",
+			writes: []string{"2020-01-01T00:00:02Z foo\n", "2020-01-01T00:00:01Z bar\n"},
+			want:   "foo\nbar\n",
+		},
+		{
+			name:   "lines without timestamps",
+			writes: []string{"foo\n", "foo\n"},
+			want:   "foo\nfoo\n",
+		},
+		{
+			name:   "partial line flushed",
+			writes: []string{"2020-01-01T00:00:01Z foo"},
+			want:   "foo",
+		},
+	}
+
+	// run tests
+	for _, test := range tests {
+		got := new(bytes.Buffer)
+		w := newTailWriter(got)
+
+		for _, write := range test.writes {
+			_, err := w.Write([]byte(write))
+			if err != nil {
+				t.Errorf("%s: Write returned err: %v", test.name, err)
+			}
+		}
+
+		err := w.Flush()
+		if err != nil {
+			t.Errorf("%s: Flush returned err: %v", test.name, err)
+		}
+
+		if got.String() != test.want {
+			t.Errorf("%s: tailWriter wrote %q, want %q", test.name, got.String(), test.want)
+		}
+	}
+}
+
+func TestDocker_tailWriter_Reset(t *testing.T) {
+	// setup types
+	got := new(bytes.Buffer)
+	w := newTailWriter(got)
+
+	want := "foo\nbar\nbaz\nqux\n"
+
+	// run test
+	_, err := w.Write([]byte("2020-01-01T00:00:01Z foo\n2020-01-01T00:00:02Z ba"))
+	if err != nil {
+		t.Errorf("Write should not have returned err: %v", err)
+	}
+
+	w.Reset()
+
+	if w.Since() != "2020-01-01T00:00:01Z" {
+		t.Errorf("Since is %s, want 2020-01-01T00:00:01Z", w.Since())
+	}
+
+	_, err = w.Write([]byte("2020-01-01T00:00:01Z foo\n2020-01-01T00:00:02Z bar\n"))
+	if err != nil {
+		t.Errorf("Write should not have returned err: %v", err)
+	}
+
+	// lines after the resumed stream caught up are all written
+	_, err = w.Write([]byte("2020-01-01T00:00:02Z baz\n2020-01-01T00:00:01Z qux\n"))
+	if err != nil {
+		t.Errorf("Write should not have returned err: %v", err)
+	}
+
+	if got.String() != want {
+		t.Errorf("tailWriter wrote %q, want %q", got.String(), want)
+	}
+}
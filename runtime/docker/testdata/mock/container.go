@@ -3,11 +3,14 @@ package mock
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/sirupsen/logrus"
 )
@@ -57,14 +60,36 @@ func listContainers(r *http.Request) (*http.Response, error) {
 func getContainer(r *http.Request, id string) (*http.Response, error) {
 
 	logrus.Infof("Getting container with ID: %s", id)
+
+	state := &types.ContainerState{
+		Running: true,
+	}
+
+	switch {
+	// report the container as killed for exceeding its memory limit
+	case strings.Contains(id, "oomkilled"):
+		state = &types.ContainerState{
+			OOMKilled: true,
+			ExitCode:  137,
+		}
+	// report the container as killed by a signal
+	case strings.Contains(id, "killed"):
+		state = &types.ContainerState{
+			ExitCode: 137,
+		}
+	// report the container as exited with a failure
+	case strings.Contains(id, "failed"):
+		state = &types.ContainerState{
+			ExitCode: 1,
+		}
+	}
+
 	b, _ := json.Marshal(types.ContainerJSON{
 		ContainerJSONBase: &types.ContainerJSONBase{
 			ID:    id,
 			Image: "test:image",
 			Name:  "name",
-			State: &types.ContainerState{
-				Running: true,
-			},
+			State: state,
 			HostConfig: &container.HostConfig{
 				Resources: container.Resources{
 					CPUQuota:  9999,
@@ -155,11 +180,63 @@ func killContainer(r *http.Request, id string) (*http.Response, error) {
 // helper function to return the mock results from logs on a running containers
 func logsContainer(r *http.Request, id string) (*http.Response, error) {
 
-	b := []byte("Hello, Docker")
-
 	logrus.Infof("Getting logs from container with ID: %s", id)
+
+	// containers with "interrupted" in the ID drop the log stream
+	// part way through and replay the last line on reconnect
+	if strings.Contains(id, "interrupted") {
+		if len(r.URL.Query().Get("since")) > 0 {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body: ioutil.NopCloser(bytes.NewReader(logLines(r,
+					"2020-01-01T00:00:02.000000000Z Hello, Docker 2",
+					"2020-01-01T00:00:03.000000000Z Hello, Docker 3",
+				))),
+			}, nil
+		}
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body: ioutil.NopCloser(io.MultiReader(
+				bytes.NewReader(logLines(r,
+					"2020-01-01T00:00:01.000000000Z Hello, Docker 1",
+					"2020-01-01T00:00:02.000000000Z Hello, Docker 2",
+				)),
+				errReader{err: io.ErrUnexpectedEOF},
+			)),
+		}, nil
+	}
+
 	return &http.Response{
 		StatusCode: http.StatusOK,
-		Body:       ioutil.NopCloser(bytes.NewReader(b)),
+		Body: ioutil.NopCloser(bytes.NewReader(logLines(r,
+			"2020-01-01T00:00:00.000000000Z Hello, Docker",
+		))),
 	}, nil
 }
+
+// helper function to multiplex the log lines the way Docker
+// does, removing the timestamps when they were not requested
+func logLines(r *http.Request, lines ...string) []byte {
+	b := new(bytes.Buffer)
+	w := stdcopy.NewStdWriter(b, stdcopy.Stdout)
+
+	for _, line := range lines {
+		if len(r.URL.Query().Get("timestamps")) == 0 {
+			line = line[strings.Index(line, " ")+1:]
+		}
+
+		w.Write([]byte(line + "\n"))
+	}
+
+	return b.Bytes()
+}
+
+// errReader is a reader that always returns the error
+type errReader struct {
+	err error
+}
+
+func (e errReader) Read(p []byte) (int, error) {
+	return 0, e.err
+}
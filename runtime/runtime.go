@@ -6,19 +6,36 @@ package runtime
 
 import (
 	"context"
+	"errors"
 	"io"
 
 	"github.com/go-vela/types/pipeline"
 )
 
+// ErrOOMKilled is returned when inspecting a container
+// that was killed for exceeding its memory limit.
+var ErrOOMKilled = errors.New("container was killed for exceeding its memory limit")
+
+// ErrKilled is returned when inspecting a container
+// that was terminated by a signal, like SIGKILL.
+var ErrKilled = errors.New("container was killed")
+
 // Engine represents the interface for Vela integrating
 // with the different supported Runtime environments.
 type Engine interface {
 
+	// Engine Interface Functions
+
+	// Name defines a function that returns the name
+	// of the runtime the pipeline is executing on.
+	Name() string
+
 	// Container Engine Interface Functions
 
 	// InspectContainer defines a function that inspects
-	// the pipeline container.
+	// the pipeline container. ErrOOMKilled is returned
+	// if the container exceeded its memory limit and
+	// ErrKilled if it was terminated by a signal.
 	InspectContainer(context.Context, *pipeline.Container) error
 	// RemoveContainer defines a function that deletes
 	// (kill, remove) the pipeline container.
@@ -0,0 +1,12 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package secret provides the ability for Vela to resolve
+// the secrets referenced by a step from different supported
+// secret backends.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/worker/secret"
+package secret
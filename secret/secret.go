@@ -0,0 +1,20 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package secret
+
+import (
+	"context"
+
+	"github.com/go-vela/types/library"
+)
+
+// Resolver represents the interface for Vela resolving the
+// secrets referenced by a step from a secret backend.
+type Resolver interface {
+	// Resolve defines a function that returns the secret
+	// referenced in the backend with its value and the
+	// events and images it is allowed for.
+	Resolve(context.Context, string) (*library.Secret, error)
+}
@@ -0,0 +1,11 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+// Package vault provides the ability for Vela to
+// resolve secrets from a HashiCorp Vault backend.
+//
+// Usage:
+//
+// 	import "github.com/go-vela/worker/secret/vault"
+package vault
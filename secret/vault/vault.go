@@ -0,0 +1,155 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-vela/types/library"
+)
+
+// defaultKey defines the default key of the
+// secret data used when none is referenced.
+const defaultKey = "value"
+
+type client struct {
+	Addr   string
+	Token  string
+	Prefix string
+	Client *http.Client
+}
+
+// New returns a secret Resolver implementation
+// that integrates with a Vault instance.
+//
+// Every secret is read under the provided path
+// prefix, e.g. "secret" or "secret/data" for
+// version 2 of the KV secrets engine.
+func New(addr, token, prefix string) (*client, error) {
+	// immediately return if no address is provided
+	if len(addr) == 0 {
+		return nil, fmt.Errorf("no address provided to vault client")
+	}
+
+	// immediately return if no token is provided
+	if len(token) == 0 {
+		return nil, fmt.Errorf("no token provided to vault client")
+	}
+
+	// create the client object
+	c := &client{
+		Addr:   strings.TrimSuffix(addr, "/"),
+		Token:  token,
+		Prefix: strings.Trim(prefix, "/"),
+		Client: http.DefaultClient,
+	}
+
+	return c, nil
+}
+
+// Resolve reads the secret referenced in the form <path>[#<key>]
+// under the prefix from Vault and returns the value for the key.
+// If no key is referenced, the "value" key is used.
+//
+// The events and images the secret is allowed for are read from
+// the "events" and "images" keys and whether commands are allowed
+// from the "allow_command" key of the secret.
+//
+// Both version 1 and version 2 of the KV secrets engine are
+// supported. For version 2, the prefix must include "data".
+func (c *client) Resolve(ctx context.Context, ref string) (*library.Secret, error) {
+	// parse the path and key from the reference
+	path, key := ref, defaultKey
+	if i := strings.LastIndex(ref, "#"); i >= 0 {
+		path, key = ref[:i], ref[i+1:]
+	}
+
+	path = strings.TrimPrefix(path, "/")
+
+	// check if a prefix is configured for the secrets
+	if len(c.Prefix) > 0 {
+		path = fmt.Sprintf("%s/%s", c.Prefix, path)
+	}
+
+	// create the request for reading the secret
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/v1/%s", c.Addr, path), nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create request for vault secret %s: %w", path, err)
+	}
+
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", c.Token)
+
+	// send API call to read the secret
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read vault secret %s: %w", path, err)
+	}
+
+	defer resp.Body.Close()
+
+	// check if the secret was read
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unable to read vault secret %s: %s", path, resp.Status)
+	}
+
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+
+	// decode the secret from the response
+	err = json.NewDecoder(resp.Body).Decode(&secret)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode vault secret %s: %w", path, err)
+	}
+
+	data := secret.Data
+
+	// check if the secret is from the KV version 2 engine
+	if nested, ok := data["data"].(map[string]interface{}); ok && strings.Contains(path, "data/") {
+		data = nested
+	}
+
+	// capture the value for the key from the secret
+	value, ok := data[key].(string)
+	if !ok {
+		return nil, fmt.Errorf("key %s not found in vault secret %s", key, path)
+	}
+
+	s := new(library.Secret)
+	s.SetName(ref)
+	s.SetValue(value)
+	s.SetEvents(stringSlice(data["events"]))
+	s.SetImages(stringSlice(data["images"]))
+
+	// capture whether commands are allowed for the secret
+	allow, _ := data["allow_command"].(bool)
+	s.SetAllowCommand(allow)
+
+	return s, nil
+}
+
+// stringSlice is a helper function to convert
+// a list from the secret data to strings.
+func stringSlice(v interface{}) []string {
+	var result []string
+
+	list, ok := v.([]interface{})
+	if !ok {
+		return result
+	}
+
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,117 @@
+// Copyright (c) 2020 Target Brands, Inc. All rights reserved.
+//
+// Use of this source code is governed by the LICENSE file in this repository.
+
+package vault
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestVault_New(t *testing.T) {
+	// setup tests
+	tests := []struct {
+		addr    string
+		token   string
+		failure bool
+	}{
+		{addr: "http://vault.example.com", token: "s.token"},
+		{addr: "", token: "s.token", failure: true},
+		{addr: "http://vault.example.com", token: "", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		_, err := New(test.addr, test.token, "secret")
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("New for %q should have returned err", test.addr)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("New for %q returned err: %v", test.addr, err)
+		}
+	}
+}
+
+func TestVault_Resolve(t *testing.T) {
+	// setup types
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "s.token" {
+			w.WriteHeader(http.StatusForbidden)
+
+			return
+		}
+
+		switch r.URL.Path {
+		case "/v1/secret/vela/token":
+			_, _ = w.Write([]byte(`{"data":{"value":"foo","password":"bar","events":["push","tag"],"images":["alpine"],"allow_command":true}}`))
+		case "/v1/secret/data/vela/token":
+			_, _ = w.Write([]byte(`{"data":{"data":{"value":"baz","events":["push"]},"metadata":{"version":1}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer s.Close()
+
+	// setup tests
+	tests := []struct {
+		prefix  string
+		ref     string
+		want    string
+		events  []string
+		images  []string
+		command bool
+		failure bool
+	}{
+		{prefix: "secret", ref: "vela/token", want: "foo", events: []string{"push", "tag"}, images: []string{"alpine"}, command: true},
+		{prefix: "/secret/", ref: "vela/token#password", want: "bar", events: []string{"push", "tag"}, images: []string{"alpine"}, command: true},
+		{prefix: "secret/data", ref: "vela/token", want: "baz", events: []string{"push"}},
+		{prefix: "", ref: "secret/vela/token", want: "foo", events: []string{"push", "tag"}, images: []string{"alpine"}, command: true},
+		{prefix: "secret", ref: "vela/token#missing", failure: true},
+		{prefix: "secret", ref: "vela/notfound", failure: true},
+	}
+
+	// run tests
+	for _, test := range tests {
+		c, _ := New(s.URL, "s.token", test.prefix)
+
+		got, err := c.Resolve(context.Background(), test.ref)
+
+		if test.failure {
+			if err == nil {
+				t.Errorf("Resolve for %s should have returned err", test.ref)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("Resolve for %s returned err: %v", test.ref, err)
+		}
+
+		if got.GetValue() != test.want {
+			t.Errorf("Resolve for %s is %v, want %v", test.ref, got.GetValue(), test.want)
+		}
+
+		if !reflect.DeepEqual(got.GetEvents(), test.events) {
+			t.Errorf("Resolve events for %s is %v, want %v", test.ref, got.GetEvents(), test.events)
+		}
+
+		if !reflect.DeepEqual(got.GetImages(), test.images) {
+			t.Errorf("Resolve images for %s is %v, want %v", test.ref, got.GetImages(), test.images)
+		}
+
+		if got.GetAllowCommand() != test.command {
+			t.Errorf("Resolve allow command for %s is %v, want %v", test.ref, got.GetAllowCommand(), test.command)
+		}
+	}
+}